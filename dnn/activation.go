@@ -0,0 +1,214 @@
+package dnn
+
+import (
+	"math"
+	"math/rand"
+)
+
+// The activation functions a layer of nodes can use.
+const (
+	Sigmoid                 = "sigmoid"
+	Tanh                    = "tanh"
+	ReLU                    = "relu"
+	Leaky_ReLU              = "leaky_relu"
+	ELU                     = "elu"
+	GELU                    = "gelu"
+	Softplus                = "softplus"
+	Linear                  = "linear"
+	Softmax                 = "softmax"
+)
+
+// leaky_slope is how steep leaky ReLU is below zero, and elu_alpha is
+// the value ELU levels off at for large negative inputs.
+const (
+	leaky_slope             = .01
+	elu_alpha               = 1.0
+)
+
+//********************************************************************
+// Name:	Is_Activation
+// Description: This function checks if a name is a known activation
+//		function.
+// Return:	returns true if the activation function exists.
+//********************************************************************
+
+func Is_Activation(name string) bool {
+	switch name {
+	case Sigmoid, Tanh, ReLU, Leaky_ReLU, ELU, GELU, Softplus, Linear, Softmax:
+		return true
+	}
+	return false
+}
+
+//********************************************************************
+// Name:	activate
+// Description: This function runs the dot product feeding a node
+//		through the activation function.
+// Return:	returns the value of the node.
+//********************************************************************
+
+func activate(name string, dot_product float64) float64 {
+	switch name {
+	case Tanh:
+		return math.Tanh(dot_product)
+	case ReLU:
+		return math.Max(dot_product, 0)
+	case Leaky_ReLU:
+		if dot_product < 0 {
+			return leaky_slope * dot_product
+		}
+		return dot_product
+	case ELU:
+		if dot_product < 0 {
+			return elu_alpha * (math.Exp(dot_product) - 1)
+		}
+		return dot_product
+	case GELU:
+		return dot_product * (1 + math.Erf(dot_product / math.Sqrt2)) / 2
+	case Softplus:
+		// written so large dot products do not overflow math.Exp
+		return math.Max(dot_product, 0) + math.Log1p(math.Exp(-math.Abs(dot_product)))
+	case Linear:
+		return dot_product
+	}
+	return 1 / (1 + math.Exp(-dot_product))
+}
+
+//********************************************************************
+// Name:	activate_layer
+// Description: This function runs every dot product feeding a layer
+//		through the activation function. Softmax needs the
+//		whole layer at once, so it is worked out here, after
+//		taking away the largest dot product so math.Exp can
+//		not overflow.
+// Return:	returns the values of the layer's nodes.
+//********************************************************************
+
+func activate_layer(name string, dot_products []float64) []float64 {
+	nodes := make([]float64, len(dot_products))
+	if name != Softmax {
+		for i := 0; i < len(dot_products); i++ {
+			nodes[i] = activate(name, dot_products[i])
+		}
+		return nodes
+	}
+
+	largest := math.Inf(-1)
+	for i := 0; i < len(dot_products); i++ {
+		largest = math.Max(largest, dot_products[i])
+	}
+	total := 0.0
+	for i := 0; i < len(dot_products); i++ {
+		nodes[i] = math.Exp(dot_products[i] - largest)
+		total += nodes[i]
+	}
+	for i := 0; i < len(nodes); i++ {
+		nodes[i] /= total
+	}
+	return nodes
+}
+
+//********************************************************************
+// Name:	derivative
+// Description: This function finds the slope of the activation
+//		function for a node, given the dot product that fed it
+//		and the value it came out as.
+// Return:	returns the derivative of the node with respect to
+//		its dot product.
+//********************************************************************
+
+func derivative(name string, dot_product float64, node float64) float64 {
+	switch name {
+	case Tanh:
+		return 1 - node * node
+	case ReLU:
+		if dot_product > 0 {
+			return 1
+		}
+		return 0
+	case Leaky_ReLU:
+		if dot_product < 0 {
+			return leaky_slope
+		}
+		return 1
+	case ELU:
+		if dot_product < 0 {
+			return node + elu_alpha
+		}
+		return 1
+	case GELU:
+		return (1 + math.Erf(dot_product / math.Sqrt2)) / 2 +
+			dot_product * math.Exp(-dot_product * dot_product / 2) / math.Sqrt(2 * math.Pi)
+	case Softplus:
+		return 1 / (1 + math.Exp(-dot_product))
+	case Linear:
+		return 1
+	}
+	return node * (1 - node)
+}
+
+//********************************************************************
+// Name:	activation_layer
+// Description: A layer that runs every value through an activation
+//		function. Softmax works on all of an input's values
+//		together, the rest work on each value alone.
+//********************************************************************
+
+type activation_layer struct {
+	no_parameters
+	name                    string
+	shape                   []int
+	inputs                  [][]float64
+	outputs                 [][]float64
+}
+
+func (layer *activation_layer) output_shape() []int {
+	return layer.shape
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function activates every input's values.
+// Return:	returns the activated values.
+//********************************************************************
+
+func (layer *activation_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	layer.inputs = inputs
+	layer.outputs = make([][]float64, len(inputs))
+	for s, values := range inputs {
+		layer.outputs[s] = activate_layer(layer.name, values)
+	}
+	return layer.outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function multiplies each gradient by the slope of
+//		the activation function, or by the softmax Jacobian.
+// Return:	returns the gradient with respect to the inputs.
+//********************************************************************
+
+func (layer *activation_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	if !inputs {
+		return nil
+	}
+	input_gradients := make([][]float64, len(output_gradients))
+	for s, gradient := range output_gradients {
+		outputs := layer.outputs[s]
+		input_gradients[s] = make([]float64, len(gradient))
+		if layer.name == Softmax {
+			weighted := 0.0
+			for k := range gradient {
+				weighted += gradient[k] * outputs[k]
+			}
+			for k := range gradient {
+				input_gradients[s][k] = outputs[k] * (gradient[k] - weighted)
+			}
+			continue
+		}
+		for k := range gradient {
+			input_gradients[s][k] = gradient[k] * derivative(layer.name, layer.inputs[s][k], outputs[k])
+		}
+	}
+	return input_gradients
+}
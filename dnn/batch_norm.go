@@ -0,0 +1,242 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	Batch_Normalization
+// Description: Which hidden layers are batch normalized and how.
+//		Layers holds a flag for each hidden layer, Momentum is
+//		how much of the running mean and variance is kept each
+//		batch, and Epsilon keeps the normalization from
+//		dividing by zero.
+//********************************************************************
+
+type Batch_Normalization struct {
+	Layers                  []bool        `json:"layers"`
+	Momentum                float64       `json:"momentum"`
+	Epsilon                 float64       `json:"epsilon"`
+}
+
+//********************************************************************
+// Name:	error_check
+// Description: This function checks the batch normalization settings
+//		against the number of hidden layers and the batch
+//		size.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (normalization *Batch_Normalization) error_check(hidden_layers int, batch_size int) error {
+	if normalization.Momentum < 0 || normalization.Momentum >= 1 {
+		return fmt.Errorf("The batch normalization momentum must be at least 0 and less than 1.")
+	}
+	if normalization.Epsilon <= 0 {
+		return fmt.Errorf("The batch normalization epsilon must be greater than 0.")
+	}
+	if normalization.Layers == nil {
+		return nil
+	}
+	if hidden_layers > 0 && len(normalization.Layers) != hidden_layers {
+		return fmt.Errorf("There must be one batch normalization flag for each hidden layer.")
+	}
+	for _, normalized := range normalization.Layers {
+		if normalized && batch_size < 2 {
+			return fmt.Errorf("Batch normalization needs a batch size of at least 2.")
+		}
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	batch_norm_layers
+// Description: This function lists which hidden layers are batch
+//		normalized, counting a hidden layer as every layer
+//		from one dense layer up to the next.
+// Return:	returns an array with one flag for each hidden layer.
+//********************************************************************
+
+func (network *Network) batch_norm_layers() []bool {
+	var layers []bool
+	for _, spec := range network.Layers {
+		if spec.Type == Dense_Layer {
+			layers = append(layers, false)
+		} else if spec.Type == Batch_Norm_Layer && len(layers) > 0 {
+			layers[len(layers) - 1] = true
+		}
+	}
+	if len(layers) == 0 {
+		return nil
+	}
+	return layers[:len(layers) - 1]
+}
+
+//********************************************************************
+// Name:	batch_norm_layer
+// Description: A layer that normalizes each of its values. While
+//		training each value is normalized with the batch's own
+//		mean and variance, then scaled by gamma and shifted by
+//		beta. The running mean and variance kept in the spec
+//		are used outside of training, and for a batch of one
+//		input, whose variance is always 0. Its parameters are
+//		one row for each value holding beta then gamma.
+//********************************************************************
+
+type batch_norm_layer struct {
+	spec                    *Layer_Spec
+	shape                   []int
+	size                    int
+	scale_shift             [][]float64
+	scale_shift_gradients   [][]float64
+	normalized              [][]float64
+	means                   []float64
+	variances               []float64
+	trained                 bool
+}
+
+func (layer *batch_norm_layer) output_shape() []int {
+	return layer.shape
+}
+
+func (layer *batch_norm_layer) shapes() [][2]int {
+	return [][2]int{{layer.size, 2}}
+}
+
+func (layer *batch_norm_layer) bind(parameters [][][]float64, gradients [][][]float64) {
+	layer.scale_shift = parameters[0]
+	if gradients != nil {
+		layer.scale_shift_gradients = gradients[0]
+	}
+}
+
+func (layer *batch_norm_layer) parameters() [][][]float64 {
+	return [][][]float64{layer.scale_shift}
+}
+
+func (layer *batch_norm_layer) gradients() [][][]float64 {
+	return [][][]float64{layer.scale_shift_gradients}
+}
+
+func (layer *batch_norm_layer) regularized() bool {
+	return false
+}
+
+//********************************************************************
+// Name:	initialize
+// Description: This function starts every value with a shift of 0
+//		and a scale of 1.
+//********************************************************************
+
+func (layer *batch_norm_layer) initialize(parameters [][][]float64, initialization *Initialization, generator *rand.Rand) {
+	for j := range parameters[0] {
+		parameters[0][j][0] = 0
+		parameters[0][j][1] = 1
+	}
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function normalizes every input's values, with
+//		the batch's statistics while training on at least two
+//		inputs and the running ones otherwise.
+// Return:	returns the scaled and shifted values.
+//********************************************************************
+
+func (layer *batch_norm_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	size := len(inputs)
+	layer.means = layer.spec.Running_Mean
+	layer.variances = layer.spec.Running_Variance
+	layer.trained = training && size > 1
+	if layer.trained {
+		layer.means = make([]float64, layer.size)
+		layer.variances = make([]float64, layer.size)
+		for j := 0; j < layer.size; j++ {
+			for s := 0; s < size; s++ {
+				layer.means[j] += inputs[s][j]
+			}
+			layer.means[j] /= float64(size)
+			for s := 0; s < size; s++ {
+				layer.variances[j] += (inputs[s][j] - layer.means[j]) * (inputs[s][j] - layer.means[j])
+			}
+			layer.variances[j] /= float64(size)
+		}
+	}
+
+	outputs := make([][]float64, size)
+	layer.normalized = make([][]float64, size)
+	for s, values := range inputs {
+		outputs[s] = make([]float64, layer.size)
+		layer.normalized[s] = make([]float64, layer.size)
+		for j, value := range values {
+			layer.normalized[s][j] = (value - layer.means[j]) / math.Sqrt(layer.variances[j] + layer.spec.Epsilon)
+			outputs[s][j] = layer.scale_shift[j][1] * layer.normalized[s][j] + layer.scale_shift[j][0]
+		}
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function adds the gradients of gamma and beta,
+//		and passes the gradient back through the
+//		normalization. Every input's normalized value depends
+//		on the whole batch's mean and variance, so each input
+//		gradient depends on the whole batch's gradients too,
+//		unless the running statistics were used.
+// Return:	returns the gradient with respect to the inputs.
+//********************************************************************
+
+func (layer *batch_norm_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	size := len(output_gradients)
+	input_gradients := make([][]float64, size)
+	for s := range input_gradients {
+		input_gradients[s] = make([]float64, layer.size)
+	}
+	for j := 0; j < layer.size; j++ {
+		sum, weighted_sum := 0.0, 0.0
+		for s := 0; s < size; s++ {
+			sum += output_gradients[s][j]
+			weighted_sum += output_gradients[s][j] * layer.normalized[s][j]
+		}
+		layer.scale_shift_gradients[j][0] += sum
+		layer.scale_shift_gradients[j][1] += weighted_sum
+		scale := layer.scale_shift[j][1] / (float64(size) * math.Sqrt(layer.variances[j] + layer.spec.Epsilon))
+		if !layer.trained {
+			for s := 0; s < size; s++ {
+				input_gradients[s][j] = scale * float64(size) * output_gradients[s][j]
+			}
+			continue
+		}
+		for s := 0; s < size; s++ {
+			input_gradients[s][j] = scale * (float64(size) * output_gradients[s][j] - sum -
+				layer.normalized[s][j] * weighted_sum)
+		}
+	}
+	if !inputs {
+		return nil
+	}
+	return input_gradients
+}
+
+//********************************************************************
+// Name:	update
+// Description: This function moves the running mean and variance
+//		toward the last batch's, unless the last batch was
+//		normalized with them. The variance is corrected for
+//		the batch size before it is averaged in.
+//********************************************************************
+
+func (layer *batch_norm_layer) update() {
+	if !layer.trained {
+		return
+	}
+	momentum := layer.spec.Momentum
+	size := len(layer.normalized)
+	correction := float64(size) / float64(size - 1)
+	for j := 0; j < layer.size; j++ {
+		layer.spec.Running_Mean[j] = momentum * layer.spec.Running_Mean[j] + (1 - momentum) * layer.means[j]
+		layer.spec.Running_Variance[j] = momentum * layer.spec.Running_Variance[j] + (1 - momentum) * layer.variances[j] * correction
+	}
+}
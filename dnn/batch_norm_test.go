@@ -0,0 +1,53 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestBatchNormRaggedBatch(t *testing.T) {
+	spec := &Layer_Spec{Type: Batch_Norm_Layer, Momentum: .9, Epsilon: 1e-5,
+		Running_Mean: []float64{1, -1}, Running_Variance: []float64{4, .25}}
+	layer := &batch_norm_layer{spec: spec, shape: []int{2}, size: 2}
+	layer.bind([][][]float64{{{.5, 2}, {-.5, 3}}}, [][][]float64{{{0, 0}, {0, 0}}})
+
+	outputs := layer.forward([][]float64{{3, -2}}, true, nil)
+	gradients := layer.backward([][]float64{{1, 1}}, true)
+	for j, value := range []float64{3, -2} {
+		deviation := math.Sqrt(spec.Running_Variance[j] + spec.Epsilon)
+		normalized := (value - spec.Running_Mean[j]) / deviation
+		if math.Abs(outputs[0][j] - (layer.scale_shift[j][1] * normalized + layer.scale_shift[j][0])) > 1e-12 {
+			t.Errorf("Value %d of a batch of one input was %g, but the running statistics give %g.",
+				j, outputs[0][j], layer.scale_shift[j][1] * normalized + layer.scale_shift[j][0])
+		}
+		if math.Abs(gradients[0][j] - layer.scale_shift[j][1] / deviation) > 1e-12 {
+			t.Errorf("The gradient of value %d of a batch of one input was %g, but should be %g.",
+				j, gradients[0][j], layer.scale_shift[j][1] / deviation)
+		}
+		if math.Abs(layer.scale_shift_gradients[j][1] - normalized) > 1e-12 {
+			t.Errorf("The gradient of scale %d for a batch of one input was %g, but should be %g.",
+				j, layer.scale_shift_gradients[j][1], normalized)
+		}
+	}
+	layer.update()
+	if fmt.Sprint(spec.Running_Mean, spec.Running_Variance) != "[1 -1] [4 0.25]" {
+		t.Errorf("A batch of one input moved the running statistics to %v and %v.", spec.Running_Mean, spec.Running_Variance)
+	}
+
+	layer.forward([][]float64{{3, -2}, {1, 0}}, true, nil)
+	layer.update()
+	if spec.Running_Mean[0] == 1 || spec.Running_Variance[0] == 4 {
+		t.Error("A batch of two inputs did not move the running statistics.")
+	}
+}
+
+func TestGradientCheckBatchNormSingleInput(t *testing.T) {
+	config := gradient_check_config([]int{4, 3}, Tanh, Softmax, Categorical_Cross_Entropy)
+	config.Batch_Normalization.Layers = []bool{true, true}
+	network, data := gradient_check_setup(config)
+	result := network.Gradient_Check(config, data[:1], 1e-5)
+	if !result.Passed(Gradient_Check_Tolerance) {
+		t.Error(result)
+	}
+}
@@ -0,0 +1,190 @@
+package dnn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// Binary_Magic starts every binary model file, and Binary_Version is
+// bumped whenever the layout of the binary container changes.
+const (
+	Binary_Magic            = "DNNB"
+	Binary_Version          = 1
+)
+
+//********************************************************************
+// Name:	Write_Binary
+// Description: This function writes the model in the binary format.
+//		The file is laid out as
+//			magic        4 bytes  "DNNB"
+//			version      uint16
+//			precision    uint16   32 or 64
+//			header size  uint32
+//			header       json of the model without weights
+//			layer count  uint32
+//			each layer   uint32 rows, uint32 columns, then
+//			             rows * columns weights
+//			checksum     uint32   crc32 of everything else
+//		with every number stored little-endian.
+// Return:	returns an error if the model could not be written.
+//********************************************************************
+
+func (model *Model) Write_Binary(writer io.Writer, precision int) error {
+	if precision != 32 && precision != 64 {
+		return fmt.Errorf("The binary model precision must be 32 or 64, not %d.", precision)
+	}
+
+	// The header is the model with the weights left out, they are stored after it.
+	header := *model
+	network := *model.Network
+	network.Weights = nil
+	header.Network = &network
+	header_json, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("Error while marshaling the model header into JSON.\n%v", err)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(Binary_Magic)
+	binary.Write(&buffer, binary.LittleEndian, uint16(Binary_Version))
+	binary.Write(&buffer, binary.LittleEndian, uint16(precision))
+	binary.Write(&buffer, binary.LittleEndian, uint32(len(header_json)))
+	buffer.Write(header_json)
+
+	var scratch [8]byte
+	weights := model.Network.Weights
+	binary.Write(&buffer, binary.LittleEndian, uint32(len(weights)))
+	for layer_index := 0; layer_index < len(weights); layer_index++ {
+		columns := 0
+		if len(weights[layer_index]) > 0 {
+			columns = len(weights[layer_index][0])
+		}
+		binary.Write(&buffer, binary.LittleEndian, uint32(len(weights[layer_index])))
+		binary.Write(&buffer, binary.LittleEndian, uint32(columns))
+		for node_index := 0; node_index < len(weights[layer_index]); node_index++ {
+			if len(weights[layer_index][node_index]) != columns {
+				return fmt.Errorf("Layer %d of the network does not have the same number of weights for every node.", layer_index)
+			}
+			for weight_index := 0; weight_index < columns; weight_index++ {
+				weight := weights[layer_index][node_index][weight_index]
+				if precision == 32 {
+					binary.LittleEndian.PutUint32(scratch[:4], math.Float32bits(float32(weight)))
+					buffer.Write(scratch[:4])
+				} else {
+					binary.LittleEndian.PutUint64(scratch[:8], math.Float64bits(weight))
+					buffer.Write(scratch[:8])
+				}
+			}
+		}
+	}
+	binary.Write(&buffer, binary.LittleEndian, crc32.ChecksumIEEE(buffer.Bytes()))
+
+	_, err = writer.Write(buffer.Bytes())
+	return err
+}
+
+//********************************************************************
+// Name:	Read_Binary_Model
+// Description: This function reads a model written by Write_Binary,
+//		checking the magic number, version and checksum.
+// Return:	returns a pointer to the model, or an error if the
+//		data is not a valid binary model.
+//********************************************************************
+
+func Read_Binary_Model(reader io.Reader) (*Model, error) {
+	file, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !Is_Binary_Model(file) || len(file) < 16 {
+		return nil, fmt.Errorf("The data is not a binary model.")
+	}
+	body := file[:len(file) - 4]
+	if binary.LittleEndian.Uint32(file[len(file) - 4:]) != crc32.ChecksumIEEE(body) {
+		return nil, fmt.Errorf("The binary model's checksum does not match, the file is corrupted.")
+	}
+
+	buffer := bytes.NewReader(body[len(Binary_Magic):])
+	var version, precision uint16
+	var header_size uint32
+	binary.Read(buffer, binary.LittleEndian, &version)
+	binary.Read(buffer, binary.LittleEndian, &precision)
+	binary.Read(buffer, binary.LittleEndian, &header_size)
+	if version > Binary_Version {
+		return nil, fmt.Errorf("The binary model is version %d, but only versions up to %d are supported.",
+			version, Binary_Version)
+	}
+	if precision != 32 && precision != 64 {
+		return nil, fmt.Errorf("The binary model has an unknown precision of %d.", precision)
+	}
+	if int64(header_size) > int64(buffer.Len()) {
+		return nil, fmt.Errorf("The binary model's header is cut short.")
+	}
+
+	header_json := make([]byte, header_size)
+	buffer.Read(header_json)
+	var model Model
+	err = json.Unmarshal(header_json, &model)
+	if err != nil {
+		return nil, fmt.Errorf("Error occured when reading the binary model's header\n%v", err)
+	}
+	if model.Network == nil {
+		return nil, fmt.Errorf("The binary model's header has no network.")
+	}
+
+	var layer_count uint32
+	err = binary.Read(buffer, binary.LittleEndian, &layer_count)
+	if err != nil {
+		return nil, fmt.Errorf("The binary model's weights are cut short.")
+	}
+	weight_size := int64(precision / 8)
+	var weights [][][]float64
+	for layer_index := uint32(0); layer_index < layer_count; layer_index++ {
+		var rows, columns uint32
+		binary.Read(buffer, binary.LittleEndian, &rows)
+		err = binary.Read(buffer, binary.LittleEndian, &columns)
+		if err != nil || int64(rows) * int64(columns) * weight_size > int64(buffer.Len()) {
+			return nil, fmt.Errorf("The binary model's weights are cut short.")
+		}
+		// The weights are sliced out of the data directly, reading them one at a
+		// time through binary.Read is far too slow for MNIST sized networks.
+		data := make([]byte, int64(rows) * int64(columns) * weight_size)
+		buffer.Read(data)
+		var layer [][]float64
+		for node_index := uint32(0); node_index < rows; node_index++ {
+			node := make([]float64, columns)
+			for weight_index := uint32(0); weight_index < columns; weight_index++ {
+				offset := (int64(node_index) * int64(columns) + int64(weight_index)) * weight_size
+				if precision == 32 {
+					node[weight_index] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[offset:])))
+				} else {
+					node[weight_index] = math.Float64frombits(binary.LittleEndian.Uint64(data[offset:]))
+				}
+			}
+			layer = append(layer, node)
+		}
+		weights = append(weights, layer)
+	}
+	if buffer.Len() != 0 {
+		return nil, fmt.Errorf("The binary model has %d unexpected bytes after the weights.", buffer.Len())
+	}
+	model.Network.Weights = weights
+	return &model, nil
+}
+
+//********************************************************************
+// Name:	Is_Binary_Model
+// Description: This function checks if some data starts with the
+//		binary model magic number.
+// Return:	returns true if the data is a binary model.
+//********************************************************************
+
+func Is_Binary_Model(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Binary_Magic))
+}
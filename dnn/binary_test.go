@@ -0,0 +1,70 @@
+package dnn
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	config, network, _ := gradient_check_network([]int{5, 4}, Sigmoid, Sigmoid, Squared_Error_Loss)
+	for _, precision := range []int{32, 64} {
+		t.Run(fmt.Sprint(precision), func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := New_Model(network, config).Write_Binary(&buffer, precision); err != nil {
+				t.Fatal(err)
+			}
+			model, err := Read_Binary_Model(bytes.NewReader(buffer.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected [][][]float64
+			for _, layer := range network.Weights {
+				var rows [][]float64
+				for _, row := range layer {
+					var weights []float64
+					for _, weight := range row {
+						if precision == 32 {
+							weight = float64(float32(weight))
+						}
+						weights = append(weights, weight)
+					}
+					rows = append(rows, weights)
+				}
+				expected = append(expected, rows)
+			}
+			if fmt.Sprint(model.Network.Weights) != fmt.Sprint(expected) {
+				t.Error("The loaded weights do not match the saved weights.")
+			}
+			if describe_layers(model.Network.Layers) != describe_layers(network.Layers) {
+				t.Errorf("The loaded layers are %s, but the saved layers are %s.",
+					describe_layers(model.Network.Layers), describe_layers(network.Layers))
+			}
+		})
+	}
+}
+
+func TestBinaryCorrupted(t *testing.T) {
+	config, network, _ := gradient_check_network([]int{3}, Sigmoid, Sigmoid, Squared_Error_Loss)
+	var buffer bytes.Buffer
+	if err := New_Model(network, config).Write_Binary(&buffer, 64); err != nil {
+		t.Fatal(err)
+	}
+	saved := buffer.Bytes()
+	for _, position := range []int{len(saved) / 4, len(saved) / 2, len(saved) - 10} {
+		t.Run(fmt.Sprintf("flipped byte %d", position), func(t *testing.T) {
+			corrupted := append([]byte(nil), saved...)
+			corrupted[position] ^= 0x40
+			_, err := Read_Binary_Model(bytes.NewReader(corrupted))
+			if err == nil || !strings.Contains(err.Error(), "checksum") {
+				t.Errorf("A model with a flipped byte was not caught by its checksum: %v", err)
+			}
+		})
+	}
+	t.Run("truncated", func(t *testing.T) {
+		if _, err := Read_Binary_Model(bytes.NewReader(saved[:len(saved) - 9])); err == nil {
+			t.Error("A cut short model was loaded.")
+		}
+	})
+}
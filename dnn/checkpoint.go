@@ -0,0 +1,153 @@
+package dnn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Checkpoint_Format names the kind of file checkpoints are saved as,
+// and Checkpoint_Version is bumped whenever their layout changes.
+const (
+	Checkpoint_Format       = "deep-neural-network-checkpoint"
+	Checkpoint_Version      = 1
+)
+
+//********************************************************************
+// Name:	Training_State
+// Description: Everything besides the weights that training needs to
+//		carry on exactly where it stopped. Epoch is the number
+//		of epochs that have finished, Optimizer holds the
+//		optimizer's tensors, and History and
+//		Validation_History hold the results measured on the
+//		training and validation data before each epoch.
+//		Early_Stopping keeps the best weights seen so far, and
+//		Schedule is what the learning rate schedule remembers.
+//********************************************************************
+
+type Training_State struct {
+	Epoch                   int           `json:"epoch"`
+	Random                  Random        `json:"random"`
+	Optimizer               Optimizer_State `json:"optimizer"`
+	History                 []Evaluation  `json:"history"`
+	Validation_History      []Evaluation  `json:"validation_history"`
+	Early_Stopping          Early_Stopping `json:"early_stopping"`
+	Schedule                Schedule_State `json:"schedule"`
+}
+
+//********************************************************************
+// Name:	New_Training_State
+// Description: This function creates the state for a network that
+//		has not been trained yet. Its random numbers come from
+//		the config's seed, mixed so they are not the same ones
+//		New_Network drew the weights from.
+// Return:	returns a pointer to the new training state.
+//********************************************************************
+
+func (network *Network) New_Training_State(config *Config) *Training_State {
+	seed := config.Random_Seed
+	if seed != 0 {
+		seed ^= 0x5851f42d4c957f2d
+	}
+	return &Training_State{
+		Random    : *New_Random(seed),
+		Optimizer : Optimizer_State{
+			First_Moment  : network.create_weights(),
+			Second_Moment : network.create_weights(),
+		},
+	}
+}
+
+//********************************************************************
+// Name:	Checkpoint
+// Description: A snapshot of a training run, holding the model as it
+//		was after an epoch together with the training state.
+//********************************************************************
+
+type Checkpoint struct {
+	Format                  string          `json:"format"`
+	Version                 int             `json:"version"`
+	Model                   *Model          `json:"model"`
+	State                   *Training_State `json:"state"`
+}
+
+//********************************************************************
+// Name:	New_Checkpoint
+// Description: This function takes a snapshot of a training run.
+// Return:	returns a pointer to the new checkpoint.
+//********************************************************************
+
+func New_Checkpoint(network *Network, config *Config, state *Training_State) *Checkpoint {
+	return &Checkpoint{
+		Format  : Checkpoint_Format,
+		Version : Checkpoint_Version,
+		Model   : New_Model(network, config),
+		State   : state,
+	}
+}
+
+//********************************************************************
+// Name:	Save
+// Description: This function writes the checkpoint to a file as json.
+//		It is written to a temporary file first and then moved
+//		into place, so a crash while saving never leaves a
+//		half written checkpoint behind.
+// Return:	returns an error if the file could not be written.
+//********************************************************************
+
+func (checkpoint *Checkpoint) Save(file_name string) error {
+	checkpoint_json, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("Error while marshaling the checkpoint into JSON.\n%v", err)
+	}
+	err = ioutil.WriteFile(file_name + ".tmp", checkpoint_json, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(file_name + ".tmp", file_name)
+}
+
+//********************************************************************
+// Name:	Load_Checkpoint
+// Description: This function reads a checkpoint saved by Save.
+// Return:	returns a pointer to the checkpoint, or an error if
+//		the file can not be used.
+//********************************************************************
+
+func Load_Checkpoint(file_name string) (*Checkpoint, error) {
+	file, err := ioutil.ReadFile(file_name)
+	if err != nil {
+		return nil, fmt.Errorf("Error occured when opening %s\n%v", file_name, err)
+	}
+
+	var checkpoint Checkpoint
+	err = json.Unmarshal(file, &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Error occured when reading the checkpoint in %s\n%v", file_name, err)
+	}
+	if checkpoint.Format != Checkpoint_Format {
+		return nil, fmt.Errorf("%s is not a deep neural network checkpoint.", file_name)
+	}
+	if checkpoint.Version != Checkpoint_Version {
+		return nil, fmt.Errorf("%s is checkpoint version %d, but only version %d is supported.",
+			file_name, checkpoint.Version, Checkpoint_Version)
+	}
+	if checkpoint.Model == nil || checkpoint.State == nil {
+		return nil, fmt.Errorf("The checkpoint in %s is missing its model or training state.", file_name)
+	}
+	err = checkpoint.Model.validate(file_name)
+	if err != nil {
+		return nil, err
+	}
+	network := checkpoint.Model.Network
+	best := &Network{Input_Count: network.Input_Count, Input_Shape: network.Input_Shape, Layers: checkpoint.State.Early_Stopping.Best_Layers}
+	if network.check_weights(checkpoint.State.Optimizer.First_Moment) != nil ||
+		network.check_weights(checkpoint.State.Optimizer.Second_Moment) != nil ||
+		(checkpoint.State.Early_Stopping.Best_Weights != nil &&
+		network.check_weights(checkpoint.State.Early_Stopping.Best_Weights) != nil) ||
+		(best.Layers != nil && best.check_weights(network.Weights) != nil) {
+		return nil, fmt.Errorf("The training state in %s does not match its network.", file_name)
+	}
+	return &checkpoint, nil
+}
@@ -0,0 +1,60 @@
+package dnn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumeMatchesUninterrupted(t *testing.T) {
+	directory, err := ioutil.TempDir("", "dnn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	config, full, data := gradient_check_network([]int{5}, Sigmoid, Sigmoid, Squared_Error_Loss)
+	config.Epoch_Count = 6
+	config.Shuffle_Data = true
+	config.Progress_Tracker = false
+	validation_data := data[:2]
+	saved, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var partial Network
+	if err := json.Unmarshal(saved, &partial); err != nil {
+		t.Fatal(err)
+	}
+
+	full_state := full.New_Training_State(config)
+	full.Resume(config, data, validation_data, full_state)
+
+	partial_config := *config
+	partial_config.Epoch_Count = 3
+	partial_config.Checkpoint_File = filepath.Join(directory, "checkpoint.json")
+	partial_config.Checkpoint_Epochs = 3
+	partial.Train(&partial_config, data, validation_data)
+
+	checkpoint, err := Load_Checkpoint(partial_config.Checkpoint_File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.State.Epoch != 3 {
+		t.Fatalf("The checkpoint was made after epoch %d, but should be after epoch 3.", checkpoint.State.Epoch)
+	}
+	resumed := checkpoint.Model.Network
+	resumed.Resume(config, data, validation_data, checkpoint.State)
+
+	if fmt.Sprint(resumed.Weights) != fmt.Sprint(full.Weights) {
+		t.Error("The resumed run did not end with the same weights as the uninterrupted run.")
+	}
+	resumed_state, _ := json.Marshal(checkpoint.State)
+	uninterrupted_state, _ := json.Marshal(full_state)
+	if string(resumed_state) != string(uninterrupted_state) {
+		t.Errorf("The resumed run's training state\n%s\ndoes not match the uninterrupted run's\n%s", resumed_state, uninterrupted_state)
+	}
+}
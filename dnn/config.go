@@ -0,0 +1,477 @@
+package dnn
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//********************************************************************
+// Name:	Config
+// Description: This holds every setting the trainer needs. It is 
+//		filled in from the json config file and passed to the
+//		Network methods explicitly.
+//********************************************************************
+
+type Config struct {
+	Data_File               string        `json:"data_file_location"`
+	Validation_File         string        `json:"validation_file_location"`
+	Test_File               string        `json:"test_file_location"`
+	Neural_Network_File     string        `json:"neural_network_file_location"`
+	Initial_Network_File    string        `json:"initial_network_file"`
+	Network_File_Format     string        `json:"neural_network_file_format"`
+	Network_File_Precision  int           `json:"neural_network_file_precision"`
+	Output_File             string        `json:"output_file_location"`
+	Checkpoint_File         string        `json:"checkpoint_file_location"`
+	Resume_From             string        `json:"resume_from"`
+	Log_File                string        `json:"log_file_location"`
+	Training                bool          `json:"true_if_training"`
+	CM_Enabled              bool          `json:"output_confusion_matrix"`
+	Test_While_Training     bool          `json:"collect_training_test_data"`
+	Progress_Tracker        bool          `json:"output_progress"`
+	Default_Target          bool          `json:"use_default_target"`
+	Fine_Tune               bool          `json:"fine_tune"`
+	Frozen_Layers           []int         `json:"frozen_layers"`
+	Hidden_Count            []int         `json:"number_of_hidden_nodes"`
+	Hidden_Activations      []string      `json:"hidden_activations"`
+	Output_Activation       string        `json:"output_activation"`
+	Dropout_Rates           []float64     `json:"dropout_rates"`
+	Regularization          Regularization `json:"regularization"`
+	Initialization          Initialization `json:"initialization"`
+	Batch_Normalization     Batch_Normalization `json:"batch_normalization"`
+	Layers                  []Layer_Spec  `json:"layers"`
+	Epoch_Update            int           `json:"epoch_update"`
+	Input_Count             int           `json:"number_of_input_values"`
+	Input_Shape             []int         `json:"input_shape"`
+	Hidden_Layers           int           `json:"number_of_hidden_layers"`
+	Output_Count            int           `json:"number_of_output_nodes"`
+	Epoch_Count             int           `json:"number_of_epochs"`
+	Batch_Size              int           `json:"batch_size"`
+	Random_Seed             int64         `json:"random_seed"`
+	Shuffle_Data            bool          `json:"shuffle_training_data"`
+	Checkpoint_Epochs       int           `json:"checkpoint_epochs"`
+	Patience                int           `json:"early_stopping_patience"`
+	Early_Stopping_Metric   string        `json:"early_stopping_metric"`
+	Min_Delta               float64       `json:"early_stopping_min_delta"`
+	Targets                 [][]float64   `json:"target_values"`
+	Class_Labels            []string      `json:"class_labels"`
+	Validation_Split        float64       `json:"validation_split"`
+	Max                     float64       `json:"value_maximum"`
+	Min                     float64       `json:"value_minimum"`
+	Momentum                float64       `json:"momentum"`
+	Optimizer               Optimizer     `json:"optimizer"`
+	Learning_Rate           float64       `json:"learning_rate"`
+	Learning_Rate_Schedule  Schedule      `json:"learning_rate_schedule"`
+	Loss                    string        `json:"loss_function"`
+	Huber_Delta             float64       `json:"huber_delta"`
+	Task                    string        `json:"task"`
+	Target_Min              []float64     `json:"target_minimum"`
+	Target_Max              []float64     `json:"target_maximum"`
+	Label_Threshold         float64       `json:"label_threshold"`
+}
+
+//********************************************************************
+//name:		Error_Check
+//purpose:	This function checks the input config variables to 
+//		make sure the software will run smoothly
+//********************************************************************
+
+func (config *Config) Error_Check() error {
+	error_string := "There are some config errors that need to be fixed before runtime.\n"
+	errors := 0
+
+	// Declared layers are checked first, since that check also rejects the
+	// hidden layer settings that can not be used along with them.
+	if config.Training == true && !config.Fine_Tune && config.Layers != nil {
+		if err := config.layers_error_check(); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+	}
+
+	csv_check := strings.Split(config.Data_File, ".")
+	if len(csv_check) == 0 || strings.ToLower(csv_check[len(csv_check) - 1]) != "csv" {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The data file passed in needs to be a csv file.\n", errors)
+	}
+	for _, file_name := range []string{config.Validation_File, config.Test_File} {
+		if file_name != "" && strings.ToLower(filepath.Ext(file_name)) != ".csv" {
+			errors++
+			error_string += fmt.Sprintf("\t%d. The data file %s needs to be a csv file.\n", errors, file_name)
+		}
+	}
+	if config.Validation_Split < 0 || config.Validation_Split >= 1 {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The validation split must be at least 0 and less than 1.\n", errors)
+	}
+	if config.Validation_Split > 0 && config.Validation_File != "" {
+		errors++
+		error_string += fmt.Sprintf("\t%d. Use either a validation file or a validation split, not both.\n", errors)
+	}
+	if err := config.loss_error_check(); err != nil {
+		errors++
+		error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+	}
+	if err := config.task_error_check(); err != nil {
+		errors++
+		error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+	}
+	if config.Training == true && !config.Fine_Tune {
+		// The network's shape only has to be in the config when training a new
+		// network, testing and fine tuning read it from the saved model instead.
+		if config.Layers == nil {
+			if len(config.Hidden_Count) != config.Hidden_Layers || config.Hidden_Layers == 0{
+				errors++
+				error_string += fmt.Sprintf("\t%d. You do not have the correct number of layers or hidden node counts.\n", errors)
+			}
+			if config.Hidden_Activations != nil && len(config.Hidden_Activations) != config.Hidden_Layers {
+				errors++
+				error_string += fmt.Sprintf("\t%d. There must be one hidden activation for each hidden layer.\n", errors)
+			}
+			for _, activation := range append(append([]string(nil), config.Hidden_Activations...), config.Output_Activation) {
+				if !Is_Activation(activation) {
+					errors++
+					error_string += fmt.Sprintf("\t%d. %s is not a known activation function.\n", errors, activation)
+				}
+			}
+			for _, activation := range config.Hidden_Activations {
+				if activation == Softmax {
+					errors++
+					error_string += fmt.Sprintf("\t%d. Only the output layer can use softmax.\n", errors)
+					break
+				}
+			}
+		}
+		if config.Output_Count <= 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Output count must be greater than 0.\n", errors)
+		}
+		if config.Input_Count <= 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Input count must be greater than 0.\n", errors)
+		}
+		if config.Input_Shape != nil && shape_size(config.Input_Shape) != config.Input_Count - 1 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. The input shape %v does not hold the %d input values.\n",
+				errors, config.Input_Shape, config.Input_Count - 1)
+		}
+		// regression and multi label targets come from each row of the data set
+		if !config.Default_Target && config.Task != Regression_Task && config.Task != Multi_Label_Task {
+			if (config.Targets == nil) {
+				errors++
+				error_string += fmt.Sprintf("%d. The default target flag is set to false, but no target valuse provided.\n", errors)
+			} else {
+				target_check := false
+				for i := 0; i < len(config.Targets); i++ {
+					if len(config.Targets) != len(config.Targets[i]) {
+						target_check = true
+					}
+					for j := 0; j < len(config.Targets[i]); j++ {
+						if config.Targets[i][j] > 1 || config.Targets[i][j] < 0 {
+							target_check = true
+						}
+					}
+				}
+				if target_check || config.Output_Count != len(config.Targets) {
+					errors++
+					error_string += fmt.Sprintf("%d. The target matrix you provided is not formatted correctly.\n", errors)
+				}
+			}
+		}
+		if config.Class_Labels != nil && len(config.Class_Labels) != config.Output_Count {
+			errors++
+			error_string += fmt.Sprintf("\t%d. There must be one class label for each output node.\n", errors)
+		}
+		if config.Min >= config.Max {
+			errors++
+			error_string += fmt.Sprintf("\t%d. The maximum must be greater than the minimum.\n", errors)
+		}
+	}
+	if config.Training == true {
+		if config.Momentum > 1 || config.Momentum < 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Momentum must be between 0 and 1.\n", errors)
+		}
+		if config.Learning_Rate > 1 || config.Learning_Rate < 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Learning rate must be between 0 and 1.\n", errors)
+		}
+		if _, err := File_Format(config.Neural_Network_File, config.Network_File_Format); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+		if err := config.Optimizer.error_check(); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+		if err := config.Learning_Rate_Schedule.error_check(); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+		if config.Learning_Rate_Schedule.Type == Plateau_Schedule && config.Validation_File == "" && config.Validation_Split == 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. The plateau learning rate schedule needs a validation file or validation split to watch.\n", errors)
+		}
+		if err := config.Regularization.error_check(config.parameter_count()); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+		// Declared layers hold their own initializers, batch normalization and
+		// dropout, so only the settings every layer shares are checked for them.
+		initialization := config.Initialization
+		normalization := config.Batch_Normalization
+		if config.Layers != nil {
+			initialization.Weights = nil
+			normalization.Layers = nil
+		}
+		if err := initialization.error_check(config.Hidden_Layers); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+		if err := normalization.error_check(config.Hidden_Layers, config.Batch_Size); err != nil {
+			errors++
+			error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+		}
+		if config.Layers == nil {
+			if err := config.dropout_error_check(); err != nil {
+				errors++
+				error_string += fmt.Sprintf("\t%d. %v\n", errors, err)
+			}
+		}
+		if config.Batch_Size <= 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Batch size must be greater than 0.\n", errors)
+		}
+		if config.Patience < 0 || config.Min_Delta < 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Early stopping patience and min delta can not be negative.\n", errors)
+		}
+		if config.Patience > 0 && config.Validation_File == "" && config.Validation_Split == 0 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Early stopping needs a validation file or validation split to watch.\n", errors)
+		}
+		if config.Early_Stopping_Metric != Validation_Loss && config.Early_Stopping_Metric != Validation_Accuracy {
+			errors++
+			error_string += fmt.Sprintf("\t%d. The early stopping metric must be %s or %s.\n", errors, Validation_Loss, Validation_Accuracy)
+		}
+		if config.Early_Stopping_Metric == Validation_Accuracy && config.Task == Regression_Task {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Regression has no accuracy, early stopping has to watch the %s.\n", errors, Validation_Loss)
+		}
+		if config.Checkpoint_Epochs < 0 || (config.Checkpoint_Epochs > 0 && config.Checkpoint_File == "") {
+			errors++
+			error_string += fmt.Sprintf("\t%d. Checkpoint epochs can not be negative, and needs a checkpoint file location.\n", errors)
+		}
+		if config.Network_File_Precision != 32 && config.Network_File_Precision != 64 {
+			errors++
+			error_string += fmt.Sprintf("\t%d. The neural network file precision must be 32 or 64.\n", errors)
+		}
+		if config.Fine_Tune && config.Neural_Network_File == "" && config.Initial_Network_File == "" {
+			errors++
+			error_string += fmt.Sprintf("\t%d. You cannot fine tune without inputing a trained neural network.\n", errors)
+		}
+		weight_layers := config.parameter_count()
+		for _, layer := range config.Frozen_Layers {
+			if layer < 0 || (weight_layers > 0 && layer >= weight_layers) {
+				errors++
+				error_string += fmt.Sprintf("\t%d. Frozen layer %d does not exist, layers of weights are numbered 0 to %d.\n", errors, layer, weight_layers - 1)
+			}
+		}
+	} else {
+		if config.Neural_Network_File == "" {
+			errors++
+			error_string += fmt.Sprintf("\t%d. You cannot perform a Test without inputing a trained neural network.\n", errors)
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf(error_string)
+	} else {
+		return nil
+	}
+}
+
+//********************************************************************
+// Name:	New_Config
+// Description: This function creates a config holding the default
+//		values for every optional setting.
+// Return:	returns a pointer to the new config.
+//********************************************************************
+
+func New_Config() *Config {
+	return &Config{
+		Training               : true,
+		Neural_Network_File    : "",
+		Network_File_Precision : 64,
+		Output_File            : "",
+		Log_File               : "",
+		CM_Enabled             : true,
+		Test_While_Training    : true,
+		Progress_Tracker       : true,
+		Default_Target         : true,
+		Output_Activation      : Sigmoid,
+		Epoch_Update           : 1,
+		Epoch_Count            : 50,
+		Batch_Size             : 1,
+		Shuffle_Data           : true,
+		Early_Stopping_Metric  : Validation_Loss,
+		Momentum               : .9,
+		Learning_Rate          : .1,
+		Huber_Delta            : 1,
+		Label_Threshold        : .5,
+		Initialization         : Initialization{Gain: 1},
+		Batch_Normalization    : Batch_Normalization{Momentum: .9, Epsilon: 1e-5},
+		Optimizer              : Optimizer{
+			Type         : SGD_Optimizer,
+			Beta_1       : .9,
+			Beta_2       : .999,
+			Decay_Rate   : .9,
+			Epsilon      : 1e-8,
+			Weight_Decay : .01,
+		},
+	}
+}
+
+//********************************************************************
+// Name:	activations
+// Description: This function lists the activation function of each
+//		layer of nodes, using sigmoid for hidden layers the
+//		config does not give one for.
+// Return:	returns an array with one activation for each layer of
+//		weights.
+//********************************************************************
+
+func (config *Config) activations() []string {
+	var activations []string
+	for i := 0; i < config.Hidden_Layers; i++ {
+		if i < len(config.Hidden_Activations) {
+			activations = append(activations, config.Hidden_Activations[i])
+		} else {
+			activations = append(activations, Sigmoid)
+		}
+	}
+	output_activation := config.Output_Activation
+	if output_activation == "" {
+		output_activation = Sigmoid
+	}
+	return append(activations, output_activation)
+}
+
+//********************************************************************
+// Name:	input_shape
+// Description: This function gives the shape of the input values,
+//		which is a flat list of them unless the config gives
+//		one.
+// Return:	returns the input shape, which does not count the bias
+//		value.
+//********************************************************************
+
+func (config *Config) input_shape() []int {
+	if config.Input_Shape != nil {
+		return config.Input_Shape
+	}
+	return []int{config.Input_Count - 1}
+}
+
+//********************************************************************
+// Name:	output_activation
+// Description: This function finds the activation function of the
+//		output layer. When the config declares its layers it
+//		is the last layer's if that is an activation layer,
+//		and linear otherwise.
+// Return:	returns the output activation.
+//********************************************************************
+
+func (config *Config) output_activation() string {
+	if config.Layers != nil {
+		if len(config.Layers) > 0 && config.Layers[len(config.Layers) - 1].Type == Activation_Layer {
+			return config.Layers[len(config.Layers) - 1].Activation
+		}
+		return Linear
+	}
+	activations := config.activations()
+	return activations[len(activations) - 1]
+}
+
+//********************************************************************
+// Name:	frozen_layers
+// Description: This function marks which layers of weights training
+//		should leave alone.
+// Return:	returns an array with one bool for each layer of
+//		weights, set to true if that layer is frozen.
+//********************************************************************
+
+func (config *Config) frozen_layers(layer_count int) []bool {
+	frozen := make([]bool, layer_count)
+	for _, layer := range config.Frozen_Layers {
+		if layer >= 0 && layer < layer_count {
+			frozen[layer] = true
+		}
+	}
+	return frozen
+}
+
+//********************************************************************
+// Name:	target_range
+// Description: This function gives the default target and non-target
+//		values. Outputs trained with cross entropy are
+//		probabilities, so they are trained towards the true
+//		probability of 1 or 0 instead of .9 and .1.
+// Return:	returns the non-target value and the target value.
+//********************************************************************
+
+func (config *Config) target_range() (float64, float64) {
+	if config.output_activation() == Softmax || config.loss_name() == Binary_Cross_Entropy {
+		return 0, 1
+	}
+	return .1, .9
+}
+
+//********************************************************************
+// Name:	target_matrix
+// Description: This function builds the target values for every
+//		input type, using the default target and non-target
+//		when the config asks for them.
+// Return:	returns a 2D array with one row of targets for each
+//		input type, or nil for regression and multi label,
+//		which build their targets from each row of data.
+//********************************************************************
+
+func (config *Config) target_matrix() [][]float64 {
+	if config.Task == Regression_Task || config.Task == Multi_Label_Task {
+		return nil
+	}
+	if !config.Default_Target {
+		return config.Targets
+	}
+	low, high := config.target_range()
+	var targets [][]float64
+	for i := 0; i < config.Output_Count; i++ {
+		var new_target []float64
+		for j := 0; j < config.Output_Count; j++ {
+			new_target = append(new_target, low)
+		}
+		new_target[i] = high
+		targets = append(targets, new_target)
+	}
+	return targets
+}
+
+//********************************************************************
+// Name:	class_labels
+// Description: This function gives the name of every input type,
+//		falling back on the input type's number when the
+//		config does not name them.
+// Return:	returns an array with one label for each input type.
+//********************************************************************
+
+func (config *Config) class_labels() []string {
+	if config.Class_Labels != nil {
+		return config.Class_Labels
+	}
+	var labels []string
+	for i := 0; i < config.Output_Count; i++ {
+		labels = append(labels, strconv.Itoa(i))
+	}
+	return labels
+}
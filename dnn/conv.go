@@ -0,0 +1,259 @@
+package dnn
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	window
+// Description: How a convolution or pooling layer slides a window
+//		over inputs shaped height x width x channels. The
+//		window is kernel_height x kernel_width, moves by the
+//		strides, and the inputs have the paddings of zeros
+//		added to each side. 1D layers work the same way on
+//		inputs that are 1 high.
+//********************************************************************
+
+type window struct {
+	height                  int
+	width                   int
+	channels                int
+	kernel_height           int
+	kernel_width            int
+	stride_height           int
+	stride_width            int
+	pad_height              int
+	pad_width               int
+	out_height              int
+	out_width               int
+}
+
+//********************************************************************
+// Name:	new_window
+// Description: This function works out the window a layer slides
+//		over its inputs. rank is the number of spatial
+//		dimensions, and the strides default to the window's
+//		size.
+// Return:	returns the window, or an error if it does not fit the
+//		inputs.
+//********************************************************************
+
+func new_window(layer_type string, input_shape []int, rank int, size_name string, size []int, stride []int,
+	padding []int) (window, error) {
+	if len(input_shape) != rank + 1 {
+		if rank == 1 {
+			return window{}, fmt.Errorf("A %s layer needs inputs shaped length x channels, but they are shaped %v.", layer_type, input_shape)
+		}
+		return window{}, fmt.Errorf("A %s layer needs inputs shaped height x width x channels, but they are shaped %v.", layer_type, input_shape)
+	}
+	sizes, err := dimensions(size_name, size, rank, 0)
+	if err != nil {
+		return window{}, err
+	}
+	if stride == nil {
+		stride = sizes
+	}
+	strides, err := dimensions("stride", stride, rank, 1)
+	if err != nil {
+		return window{}, err
+	}
+	paddings, err := dimensions("padding", padding, rank, 0)
+	if err != nil {
+		return window{}, err
+	}
+	shape := input_shape
+	if rank == 1 {
+		shape = append([]int{1}, shape...)
+		sizes = append([]int{1}, sizes...)
+		strides = append([]int{1}, strides...)
+		paddings = append([]int{0}, paddings...)
+	}
+
+	slide := window{
+		height        : shape[0],
+		width         : shape[1],
+		channels      : shape[2],
+		kernel_height : sizes[0],
+		kernel_width  : sizes[1],
+		stride_height : strides[0],
+		stride_width  : strides[1],
+		pad_height    : paddings[0],
+		pad_width     : paddings[1],
+	}
+	for i := range sizes {
+		if sizes[i] < 1 || strides[i] < 1 || paddings[i] < 0 {
+			return window{}, fmt.Errorf("A %s layer needs a %s and stride of at least 1, and a padding of at least 0.", layer_type, size_name)
+		}
+		if shape[i] + 2 * paddings[i] < sizes[i] {
+			return window{}, fmt.Errorf("The %s of the %s layer is larger than its padded inputs %v.", size_name, layer_type, input_shape)
+		}
+	}
+	slide.out_height = (slide.height + 2 * slide.pad_height - slide.kernel_height) / slide.stride_height + 1
+	slide.out_width = (slide.width + 2 * slide.pad_width - slide.kernel_width) / slide.stride_width + 1
+	return slide, nil
+}
+
+//********************************************************************
+// Name:	input_index
+// Description: This function finds the input under one spot of the
+//		window placed at an output.
+// Return:	returns the index of the spot's first channel, or -1
+//		if it falls on the padding.
+//********************************************************************
+
+func (slide *window) input_index(out_y int, out_x int, dy int, dx int) int {
+	y := out_y * slide.stride_height + dy - slide.pad_height
+	x := out_x * slide.stride_width + dx - slide.pad_width
+	if y < 0 || y >= slide.height || x < 0 || x >= slide.width {
+		return -1
+	}
+	return (y * slide.width + x) * slide.channels
+}
+
+//********************************************************************
+// Name:	conv_layer
+// Description: A convolution layer. Each filter is a row of weights
+//		with the bias in column 0, followed by a weight for
+//		every channel of every spot in the kernel, so its
+//		matrix is filters x (channels * kernel height * kernel
+//		width + 1). Each filter is run over every spot of the
+//		inputs, giving an output channel for each filter. For
+//		the initializers a filter's fan in is the size of its
+//		kernel times the channels, and the fan out is the
+//		number of filters.
+//********************************************************************
+
+type conv_layer struct {
+	weight_matrix
+	slide                   window
+	shape                   []int
+	inputs                  [][]float64
+}
+
+//********************************************************************
+// Name:	new_conv_layer
+// Description: This function builds a conv1d or conv2d layer from its
+//		spec.
+// Return:	returns the layer, or an error if the spec can not be
+//		built.
+//********************************************************************
+
+func new_conv_layer(spec *Layer_Spec, input_shape []int) (Layer, error) {
+	if spec.Filters <= 0 {
+		return nil, fmt.Errorf("A %s layer needs at least 1 filter.", spec.Type)
+	}
+	matrix, err := new_weight_matrix(spec)
+	if err != nil {
+		return nil, err
+	}
+	rank := 2
+	if spec.Type == Conv1D_Layer {
+		rank = 1
+	}
+	slide, err := new_window(spec.Type, input_shape, rank, "kernel size", spec.Kernel_Size, spec.Stride, spec.Padding)
+	if err != nil {
+		return nil, err
+	}
+	shape := []int{slide.out_height, slide.out_width, spec.Filters}
+	if rank == 1 {
+		shape = shape[1:]
+	}
+	return &conv_layer{weight_matrix: matrix, slide: slide, shape: shape}, nil
+}
+
+func (layer *conv_layer) output_shape() []int {
+	return layer.shape
+}
+
+func (layer *conv_layer) shapes() [][2]int {
+	slide := layer.slide
+	return [][2]int{{layer.spec.Filters, slide.channels * slide.kernel_height * slide.kernel_width + 1}}
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function runs every filter over every input.
+// Return:	returns the filters' dot products, with a channel for
+//		each filter.
+//********************************************************************
+
+func (layer *conv_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	layer.inputs = inputs
+	slide := &layer.slide
+	filters := len(layer.weights)
+	outputs := make([][]float64, len(inputs))
+	for s, values := range inputs {
+		outputs[s] = make([]float64, slide.out_height * slide.out_width * filters)
+		for out_y := 0; out_y < slide.out_height; out_y++ {
+			for out_x := 0; out_x < slide.out_width; out_x++ {
+				out := (out_y * slide.out_width + out_x) * filters
+				for f, row := range layer.weights {
+					dot_product := row[0]
+					for dy := 0; dy < slide.kernel_height; dy++ {
+						for dx := 0; dx < slide.kernel_width; dx++ {
+							index := slide.input_index(out_y, out_x, dy, dx)
+							if index < 0 {
+								continue
+							}
+							column := 1 + (dy * slide.kernel_width + dx) * slide.channels
+							for c := 0; c < slide.channels; c++ {
+								dot_product += row[column + c] * values[index + c]
+							}
+						}
+					}
+					outputs[s][out + f] = dot_product
+				}
+			}
+		}
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function adds the gradient of every filter weight
+//		onto the layer's gradients, one input at a time.
+// Return:	returns the gradient with respect to the inputs, or
+//		nil if they are not needed.
+//********************************************************************
+
+func (layer *conv_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	slide := &layer.slide
+	filters := len(layer.weights)
+	var input_gradients [][]float64
+	if inputs {
+		input_gradients = make([][]float64, len(output_gradients))
+	}
+	for s, gradient := range output_gradients {
+		values := layer.inputs[s]
+		if inputs {
+			input_gradients[s] = make([]float64, len(values))
+		}
+		for out_y := 0; out_y < slide.out_height; out_y++ {
+			for out_x := 0; out_x < slide.out_width; out_x++ {
+				out := (out_y * slide.out_width + out_x) * filters
+				for f, row := range layer.weight_gradients {
+					g := gradient[out + f]
+					row[0] += g
+					for dy := 0; dy < slide.kernel_height; dy++ {
+						for dx := 0; dx < slide.kernel_width; dx++ {
+							index := slide.input_index(out_y, out_x, dy, dx)
+							if index < 0 {
+								continue
+							}
+							column := 1 + (dy * slide.kernel_width + dx) * slide.channels
+							for c := 0; c < slide.channels; c++ {
+								row[column + c] += g * values[index + c]
+								if inputs {
+									input_gradients[s][index + c] += layer.weights[f][column + c] * g
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return input_gradients
+}
@@ -0,0 +1,183 @@
+package dnn
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//********************************************************************
+// Name:	Input
+// Description: A single row of the data set. Values holds the bias
+//		followed by the normalized inputs, Target holds the
+//		values the outputs are trained towards, and Position
+//		is the class the row belongs to. Regression rows have
+//		no class, so their Position is always 0. Multi label
+//		rows mark each label they have in Labels, and their
+//		Position is the first of them.
+//********************************************************************
+
+type Input struct {
+	Values   []float64
+	Target   []float64
+	Position int
+	Labels   []bool
+}
+
+//********************************************************************
+// Name:	Read_CSV
+// Description: This function reads a csv file, and puts it's data
+//		into an array of inputs scaled and targeted the way
+//		the config describes.
+// Return:	returns an array of the type Input, or an error if the
+//		file could not be read.
+//********************************************************************
+
+func Read_CSV(config *Config, file_name string) ([]Input, error) {
+	var data []Input
+	targets := config.target_matrix()
+
+	log.Print("Reading data file ", file_name)
+	file, err := os.Open(file_name)
+	if err != nil {
+		return nil, fmt.Errorf("Error occured when opening %s\n%v", file_name, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(bufio.NewReader(file))
+	// every row's columns are checked below, so the error can say what they should hold
+	reader.FieldsPerRecord = -1
+	//a for loop that continues until it reaches the end of the file.
+	for {
+		line, err := reader.Read()
+		//error check for the end of a file.
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Error occured while reading through %s\n\t\t%v", file_name, err)
+		}
+
+		var new_data_point Input
+		first_value := 1
+		if config.Task == Regression_Task {
+			// The first columns hold the real valued targets, one for each output.
+			first_value = config.Output_Count
+		}
+		if len(line) != first_value + config.Input_Count - 1 {
+			return nil, fmt.Errorf("Line %d of the csv input file has %d columns, but it should have %d target columns and %d input values.",
+				len(data) + 1, len(line), first_value, config.Input_Count - 1)
+		}
+		if config.Task == Regression_Task {
+			for i := 0; i < config.Output_Count; i++ {
+				target, err := strconv.ParseFloat(line[i], 64)
+				if err != nil {
+					return nil, fmt.Errorf("Error occured while converting target %d on line %d of the csv input file.\n\t\t%v",
+						i + 1, len(data) + 1, err)
+				}
+				new_data_point.Target = append(new_data_point.Target, config.scale_target(i, target))
+			}
+		} else if config.Task == Multi_Label_Task {
+			new_data_point.Labels, err = config.parse_labels(line[0])
+			if err != nil {
+				return nil, fmt.Errorf("Error occured while reading the labels on line %d of the csv input file.\n\t\t%v",
+					len(data) + 1, err)
+			}
+			low, high := config.target_range()
+			for _, label := range new_data_point.Labels {
+				if label {
+					new_data_point.Target = append(new_data_point.Target, high)
+				} else {
+					new_data_point.Target = append(new_data_point.Target, low)
+				}
+			}
+			for k := len(new_data_point.Labels) - 1; k >= 0; k-- {
+				if new_data_point.Labels[k] {
+					new_data_point.Position = k
+				}
+			}
+		} else {
+			//Checking the Input's position in the input type array
+			new_data_point.Position, err = strconv.Atoi(line[0])
+			if err != nil {
+				return nil, fmt.Errorf("Error occured while converting the input type on line %d of the csv input file.\n\t\t%v",
+					len(data) + 1, err)
+			}
+			if new_data_point.Position < 0 || new_data_point.Position >= config.Output_Count {
+				return nil, fmt.Errorf("The input type %d on line %d of the csv input file is not between 0 and %d.",
+					new_data_point.Position, len(data) + 1, config.Output_Count - 1)
+			}
+			new_data_point.Target = targets[new_data_point.Position]
+		}
+
+		new_data_point.Values = append(new_data_point.Values, 1)
+		//parse through each data_entry and adds it to the data point.
+		for i := first_value; i < len(line); i++ {
+			data_entry, err := strconv.ParseFloat(line[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Error occured while converting input on row %d on line %d of the csv input file.\n\t\t%v",
+					i + 1, len(data) + 1, err)
+			}
+			new_data_point.Values = append(new_data_point.Values, (data_entry - config.Min) / (config.Max - config.Min))
+		}
+		data = append(data, new_data_point)
+	}
+	log.Print("Finished loading all training data from memory.")
+	return data, nil
+}
+
+//********************************************************************
+// Name:	parse_labels
+// Description: This function reads the labels of a multi label row,
+//		which are the numbers of its labels separated by
+//		semicolons. An empty column means the row has none.
+// Return:	returns an array marking which labels the row has, or
+//		an error if a label is not a number of an output node.
+//********************************************************************
+
+func (config *Config) parse_labels(column string) ([]bool, error) {
+	labels := make([]bool, config.Output_Count)
+	if strings.TrimSpace(column) == "" {
+		return labels, nil
+	}
+	for _, field := range strings.Split(column, ";") {
+		label, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if label < 0 || label >= config.Output_Count {
+			return nil, fmt.Errorf("The label %d is not between 0 and %d.", label, config.Output_Count - 1)
+		}
+		labels[label] = true
+	}
+	return labels, nil
+}
+
+//********************************************************************
+// Name:	Split_Data
+// Description: This function carves a random fraction out of the
+//		data, for example to hold it back for validation. The
+//		split is drawn from the seed, so the same seed always
+//		splits the data the same way.
+// Return:	returns the data that was kept and the data that was
+//		carved out.
+//********************************************************************
+
+func Split_Data(data []Input, fraction float64, seed int64) ([]Input, []Input) {
+	order := New_Random(seed).generator().Perm(len(data))
+	split_count := int(float64(len(data)) * fraction)
+
+	var kept, split []Input
+	for i, data_index := range order {
+		if i < split_count {
+			split = append(split, data[data_index])
+		} else {
+			kept = append(kept, data[data_index])
+		}
+	}
+	return kept, split
+}
@@ -0,0 +1,55 @@
+package dnn
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCSVColumns(t *testing.T) {
+	directory, err := ioutil.TempDir("", "dnn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	config, _, _ := gradient_check_network([]int{2}, Tanh, Sigmoid, Squared_Error_Loss)
+	config.Min = 0
+	config.Max = 10
+	tests := []struct {
+		name                    string
+		task                    string
+		rows                    string
+		valid                   bool
+	}{
+		{"classification", Classification_Task, "0,1,2,3\n2,4,5,6\n", true},
+		{"too long", Classification_Task, "0,1,2,3\n1,1,2,3,4\n", false},
+		{"too short", Classification_Task, "0,1,2,3\n1,1,2\n", false},
+		{"regression", Regression_Task, "1,2,3,4,5,6\n", true},
+		{"regression too short", Regression_Task, "1,2,3,4,5\n", false},
+		{"multi label", Multi_Label_Task, "0;2,1,2,3\n", true},
+		{"multi label too long", Multi_Label_Task, "0;2,1,2,3,4\n", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file_name := filepath.Join(directory, "data.csv")
+			if err := ioutil.WriteFile(file_name, []byte(test.rows), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config.Task = test.task
+			data, err := Read_CSV(config, file_name)
+			if test.valid && err != nil {
+				t.Fatal(err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("A row with the wrong number of columns was read.")
+			}
+			for _, data_point := range data {
+				if len(data_point.Values) != config.Input_Count {
+					t.Errorf("A row was read with %d values, but it should have %d.", len(data_point.Values), config.Input_Count)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package dnn
+
+import (
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	dense_layer
+// Description: A fully connected layer. Each of its nodes is the dot
+//		product of every input with that node's row of
+//		weights, where column 0 of the row is the node's bias.
+//********************************************************************
+
+type dense_layer struct {
+	weight_matrix
+	input_count             int
+	inputs                  [][]float64
+}
+
+func (layer *dense_layer) output_shape() []int {
+	return []int{layer.spec.Nodes}
+}
+
+func (layer *dense_layer) shapes() [][2]int {
+	return [][2]int{{layer.spec.Nodes, layer.input_count + 1}}
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function finds the dot product feeding each node
+//		for every input.
+// Return:	returns the dot products.
+//********************************************************************
+
+func (layer *dense_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	layer.inputs = inputs
+	outputs := make([][]float64, len(inputs))
+	for s, values := range inputs {
+		outputs[s] = make([]float64, len(layer.weights))
+		for k, row := range layer.weights {
+			dot_product := row[0]
+			for j := 0; j < len(values); j++ {
+				dot_product += row[j + 1] * values[j]
+			}
+			outputs[s][k] = dot_product
+		}
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function adds the gradient of every weight onto
+//		the layer's gradients, one input at a time.
+// Return:	returns the gradient with respect to the inputs, or
+//		nil if they are not needed.
+//********************************************************************
+
+func (layer *dense_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	var input_gradients [][]float64
+	if inputs {
+		input_gradients = make([][]float64, len(output_gradients))
+	}
+	for s, gradient := range output_gradients {
+		values := layer.inputs[s]
+		for k, row := range layer.weight_gradients {
+			row[0] += gradient[k]
+			for j := 0; j < len(values); j++ {
+				row[j + 1] += gradient[k] * values[j]
+			}
+		}
+		if inputs {
+			input_gradients[s] = make([]float64, len(values))
+			for j := range values {
+				for k := range layer.weights {
+					input_gradients[s][j] += layer.weights[k][j + 1] * gradient[k]
+				}
+			}
+		}
+	}
+	return input_gradients
+}
@@ -0,0 +1,132 @@
+package dnn
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	dropout_error_check
+// Description: This function checks the config's dropout rates.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (config *Config) dropout_error_check() error {
+	if config.Dropout_Rates == nil {
+		return nil
+	}
+	if config.Hidden_Layers > 0 && len(config.Dropout_Rates) != config.Hidden_Layers {
+		return fmt.Errorf("There must be one dropout rate for each hidden layer.")
+	}
+	for _, rate := range config.Dropout_Rates {
+		if rate < 0 || rate >= 1 {
+			return fmt.Errorf("Each dropout rate must be at least 0 and less than 1.")
+		}
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	dropout_layer
+// Description: A layer that drops values while training. Every value
+//		is dropped with the layer's rate, and the values that
+//		are kept are scaled up by 1 / (1 - rate) so the next
+//		layer sees the same total on average as it does
+//		without dropout. Nothing is dropped outside of
+//		training, or when there is no generator to draw from.
+//********************************************************************
+
+type dropout_layer struct {
+	no_parameters
+	rate                    float64
+	shape                   []int
+	masks                   [][]float64
+}
+
+func (layer *dropout_layer) output_shape() []int {
+	return layer.shape
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function picks the values dropped for every
+//		input and scales the rest.
+// Return:	returns the values after dropout.
+//********************************************************************
+
+func (layer *dropout_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	layer.masks = nil
+	if !training || generator == nil || layer.rate == 0 {
+		return inputs
+	}
+	outputs := make([][]float64, len(inputs))
+	layer.masks = make([][]float64, len(inputs))
+	for s, values := range inputs {
+		outputs[s] = make([]float64, len(values))
+		layer.masks[s] = make([]float64, len(values))
+		for j := range values {
+			if generator.Float64() >= layer.rate {
+				layer.masks[s][j] = 1 / (1 - layer.rate)
+			}
+			outputs[s][j] = values[j] * layer.masks[s][j]
+		}
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function passes the gradient back through the
+//		values that were kept.
+// Return:	returns the gradient with respect to the inputs.
+//********************************************************************
+
+func (layer *dropout_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	if !inputs || layer.masks == nil {
+		return output_gradients
+	}
+	input_gradients := make([][]float64, len(output_gradients))
+	for s, gradient := range output_gradients {
+		input_gradients[s] = make([]float64, len(gradient))
+		for j := range gradient {
+			input_gradients[s][j] = gradient[j] * layer.masks[s][j]
+		}
+	}
+	return input_gradients
+}
+
+//********************************************************************
+// Name:	set_dropout
+// Description: This function gives each hidden layer of the network
+//		the dropout rate for it, replacing the dropout it had.
+//		A hidden layer is every layer from one dense layer up
+//		to the next, and its dropout goes at the end of it. A
+//		rate of 0 leaves the hidden layer without dropout.
+//********************************************************************
+
+func (network *Network) set_dropout(rates []float64) {
+	var kept []Layer_Spec
+	hidden := -1
+	for _, spec := range network.Layers {
+		if spec.Type == Dense_Layer {
+			hidden++
+		}
+		if spec.Type != Dropout_Layer || hidden < 0 || hidden >= len(rates) {
+			kept = append(kept, spec)
+		}
+	}
+
+	var specs []Layer_Spec
+	hidden = -1
+	for i, spec := range kept {
+		if spec.Type == Dense_Layer {
+			hidden++
+		}
+		specs = append(specs, spec)
+		ends := i + 1 < len(kept) && kept[i + 1].Type == Dense_Layer
+		if ends && hidden >= 0 && hidden < len(rates) && rates[hidden] > 0 {
+			specs = append(specs, Layer_Spec{Type: Dropout_Layer, Rate: rates[hidden]})
+		}
+	}
+	network.Layers = specs
+}
@@ -0,0 +1,46 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestDropout(t *testing.T) {
+	layer := &dropout_layer{rate: .25, shape: []int{1000}}
+	inputs := [][]float64{make([]float64, 1000)}
+	for j := range inputs[0] {
+		inputs[0][j] = float64(j % 7) + 1
+	}
+	if fmt.Sprint(layer.forward(inputs, false, New_Random(3).generator())) != fmt.Sprint(inputs) {
+		t.Error("Dropout changed the values outside of training.")
+	}
+	if fmt.Sprint(layer.forward(inputs, true, nil)) != fmt.Sprint(inputs) {
+		t.Error("Dropout changed the values without a random number generator.")
+	}
+
+	outputs := layer.forward(inputs, true, New_Random(3).generator())
+	dropped := 0
+	for j, output := range outputs[0] {
+		if output == 0 {
+			dropped++
+		} else if math.Abs(output - inputs[0][j] / .75) > 1e-12 {
+			t.Fatalf("Value %d was %g while training, but should have been dropped or scaled up to %g.",
+				j, output, inputs[0][j] / .75)
+		}
+	}
+	if dropped < 200 || dropped > 300 {
+		t.Errorf("Dropout dropped %d of 1000 values at a rate of .25.", dropped)
+	}
+
+	gradients := [][]float64{make([]float64, 1000)}
+	for j := range gradients[0] {
+		gradients[0][j] = 1
+	}
+	gradients = layer.backward(gradients, true)
+	for j, gradient := range gradients[0] {
+		if (outputs[0][j] == 0) != (gradient == 0) {
+			t.Fatalf("Value %d was dropped while training, but its gradient was %g.", j, gradient)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package dnn
+
+import (
+	"fmt"
+	"log"
+)
+
+// The metrics early stopping can watch.
+const (
+	Validation_Loss         = "validation_loss"
+	Validation_Accuracy     = "validation_accuracy"
+)
+
+//********************************************************************
+// Name:	Early_Stopping
+// Description: The best weights early stopping has seen so far. Best
+//		is the watched metric at Best_Epoch, the number of
+//		epochs that had finished when Best_Weights was copied,
+//		and Waiting counts the epochs since it last improved.
+//		Best_Layers is copied along with the weights, since
+//		some layers keep statistics outside of them.
+//********************************************************************
+
+type Early_Stopping struct {
+	Best                    float64       `json:"best"`
+	Best_Epoch              int           `json:"best_epoch"`
+	Best_Weights            [][][]float64 `json:"best_weights"`
+	Waiting                 int           `json:"waiting"`
+	Best_Layers             []Layer_Spec  `json:"best_layers,omitempty"`
+}
+
+//********************************************************************
+// Name:	score
+// Description: This function pulls the metric the config watches out
+//		of the validation results, flipping losses so a higher
+//		score is always better.
+// Return:	returns the score of the validation results.
+//********************************************************************
+
+func (config *Config) score(validation_results Evaluation) float64 {
+	if config.Early_Stopping_Metric == Validation_Accuracy {
+		return validation_results.Accuracy
+	}
+	return -validation_results.Loss
+}
+
+//********************************************************************
+// Name:	track
+// Description: This function compares the validation results for the
+//		weights after epoch epochs against the best so far,
+//		keeping a copy of the weights if they improved on it
+//		by more than the config's min delta.
+// Return:	returns true once the metric has gone the config's
+//		patience in epochs without improving.
+//********************************************************************
+
+func (stopping *Early_Stopping) track(config *Config, network *Network, epoch int, validation_results Evaluation) bool {
+	score := config.score(validation_results)
+	if stopping.Best_Weights == nil || score > stopping.Best + config.Min_Delta {
+		stopping.Best = score
+		stopping.Best_Epoch = epoch
+		stopping.Best_Weights = copy_weights(network.Weights)
+		stopping.Best_Layers = copy_layers(network.Layers)
+		stopping.Waiting = 0
+		return false
+	}
+	stopping.Waiting++
+	return stopping.Waiting >= config.Patience
+}
+
+//********************************************************************
+// Name:	restore
+// Description: This function puts the best weights seen back into
+//		the network and logs which epoch they came from.
+//********************************************************************
+
+func (stopping *Early_Stopping) restore(config *Config, network *Network) {
+	if stopping.Best_Weights == nil {
+		return
+	}
+	network.Weights = copy_weights(stopping.Best_Weights)
+	if stopping.Best_Layers != nil {
+		network.Layers = copy_layers(stopping.Best_Layers)
+	}
+	best := fmt.Sprintf("a validation loss of %f", -stopping.Best)
+	if config.Early_Stopping_Metric == Validation_Accuracy {
+		best = fmt.Sprintf("a validation accuracy of %f%%", stopping.Best)
+	}
+	log.Print("Using the weights from after epoch #", stopping.Best_Epoch, ", which had ", best)
+}
@@ -0,0 +1,48 @@
+package dnn
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestEarlyStoppingKeepsBestWeights(t *testing.T) {
+	config, network, data := gradient_check_network([]int{5}, Sigmoid, Sigmoid, Squared_Error_Loss)
+	config.Epoch_Count = 30
+	config.Patience = 3
+	config.Progress_Tracker = false
+	// the loss keeps creeping down, so the min delta is what stops training
+	config.Min_Delta = .02
+	validation_data := data[1:]
+	saved, err := json.Marshal(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := network.New_Training_State(config)
+	network.Resume(config, data, validation_data, state)
+	best := state.Early_Stopping
+	if best.Best_Epoch == 0 || best.Best_Epoch >= state.Epoch {
+		t.Fatalf("The best epoch was %d of %d, so stopping early was not tested.", best.Best_Epoch, state.Epoch)
+	}
+	if state.Epoch != best.Best_Epoch + config.Patience {
+		t.Errorf("Training stopped after epoch %d, but should have stopped %d epochs after the best epoch %d.",
+			state.Epoch, config.Patience, best.Best_Epoch)
+	}
+
+	// training a copy for only the best epoch's number of epochs gives the weights it should have kept
+	rerun_config := *config
+	rerun_config.Epoch_Count = best.Best_Epoch
+	rerun_config.Patience = 0
+	var rerun Network
+	if err := json.Unmarshal(saved, &rerun); err != nil {
+		t.Fatal(err)
+	}
+	rerun.Train(&rerun_config, data, validation_data)
+	if fmt.Sprint(network.Weights) != fmt.Sprint(rerun.Weights) {
+		t.Error("The weights kept by early stopping are not the weights from after the best epoch.")
+	}
+	if config.score(network.Evaluate(config, validation_data)) != best.Best {
+		t.Error("The weights kept by early stopping do not score the best validation loss.")
+	}
+}
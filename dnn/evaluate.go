@@ -0,0 +1,197 @@
+package dnn
+
+import (
+	"fmt"
+)
+
+//********************************************************************
+// Name:	Evaluation
+// Description: The results of running a data set through a network.
+//		Accuracy is a percentage, Loss is the config's loss
+//		function averaged over the data set, and
+//		Confusion_Matrix is only filled in when the config has
+//		it enabled. Regression tests leave those out and fill
+//		in the error measurements instead, and multi label
+//		tests use Accuracy for the subset accuracy and add the
+//		hamming loss and the score of each label.
+//********************************************************************
+
+type Evaluation struct {
+	Task                    string        `json:"task,omitempty"`
+	Accuracy                float64       `json:"accuracy"`
+	Loss                    float64       `json:"loss"`
+	Confusion_Matrix        [][]int       `json:"confusion_matrix"`
+	Mean_Squared_Error      float64       `json:"mean_squared_error,omitempty"`
+	Root_Mean_Squared_Error float64       `json:"root_mean_squared_error,omitempty"`
+	Mean_Absolute_Error     float64       `json:"mean_absolute_error,omitempty"`
+	R_Squared               float64       `json:"r_squared,omitempty"`
+	Hamming_Loss            float64       `json:"hamming_loss,omitempty"`
+	Label_Scores            []Label_Score `json:"label_scores,omitempty"`
+}
+
+//********************************************************************
+// Name:	String
+// Description: This function formats the accuracy of the evaluation,
+//		or its error measurements for a regression test.
+// Return:	A string holding the accuracy as a percentage, or the
+//		mean squared error, root mean squared error, mean
+//		absolute error and R squared separated by commas, or
+//		the subset accuracy and hamming loss for multi label.
+//********************************************************************
+
+func (evaluation Evaluation) String() string {
+	if evaluation.Task == Regression_Task {
+		return fmt.Sprintf("%f, %f, %f, %f", evaluation.Mean_Squared_Error, evaluation.Root_Mean_Squared_Error,
+			evaluation.Mean_Absolute_Error, evaluation.R_Squared)
+	}
+	if evaluation.Task == Multi_Label_Task {
+		return fmt.Sprintf("%4f%%, %f", evaluation.Accuracy, evaluation.Hamming_Loss)
+	}
+	return fmt.Sprintf("%4f%%", evaluation.Accuracy)
+}
+
+//********************************************************************
+// Name:	progress
+// Description: This function describes the evaluation for the log,
+//		naming it after the data set it came from.
+// Return:	A string to add to the progress log.
+//********************************************************************
+
+func (evaluation Evaluation) progress(name string) string {
+	if evaluation.Task == Regression_Task {
+		return fmt.Sprintf(", %s mse, rmse, mae and r squared are %v with a loss of %f", name, evaluation, evaluation.Loss)
+	}
+	if evaluation.Task == Multi_Label_Task {
+		return fmt.Sprintf(", %s subset accuracy and hamming loss are %v with a loss of %f", name, evaluation, evaluation.Loss)
+	}
+	return fmt.Sprintf(", %s accuracy is %v with a loss of %f", name, evaluation, evaluation.Loss)
+}
+
+//********************************************************************
+// Name:	Evaluate
+// Description: This function runs a test for accuracy on the given
+//		data set using the network. It can also creates a
+//		confusion matrix when it runs. Regression and multi
+//		label tests are handed off to evaluate_regression and
+//		evaluate_multi_label. The loss includes the config's
+//		L1 and L2 penalties.
+// Return:	An Evaluation that contains the accuracy of the run,
+//		and its loss, and the confusion matrix.
+//********************************************************************
+
+func (network *Network) Evaluate(config *Config, data []Input) Evaluation {
+	var evaluation Evaluation
+	switch config.Task {
+	case Regression_Task:
+		evaluation = network.evaluate_regression(config, data)
+	case Multi_Label_Task:
+		evaluation = network.evaluate_multi_label(config, data)
+	default:
+		evaluation = network.evaluate_classification(config, data)
+	}
+	evaluation.Loss += config.Regularization.penalty(network.Weights, network.regularized_layers())
+	return evaluation
+}
+
+//********************************************************************
+// Name:	evaluate_classification
+// Description: This function runs a classification test on the given
+//		data set, filling in the confusion matrix when the
+//		config has it enabled.
+// Return:	An Evaluation holding the accuracy, loss and confusion
+//		matrix.
+//********************************************************************
+
+func (network *Network) evaluate_classification(config *Config, data []Input) Evaluation {
+	hits := 0
+	loss := 0.0
+	loss_function := config.new_loss()
+	var confusion_matrix [][]int
+	// Initializing the confusion matrix
+	if config.CM_Enabled {
+		for i := 0; i < network.Output_Count; i++ {
+			var new_line []int;
+			for j := 0; j < network.Output_Count; j++ {
+				new_line = append(new_line, 0)
+			}
+			confusion_matrix = append(confusion_matrix, new_line)
+		}
+	}
+
+	predictions := network.predict(config, data)
+	for data_index := 0; data_index < len(data); data_index++ {
+		outputs := predictions[data_index]
+		loss += loss_function.loss(outputs, data[data_index].Target)
+
+		// check for the highest dot product in the array
+		highest_product := 0
+		for input_index := 1; input_index < network.Output_Count; input_index++ {
+			if outputs[highest_product] < outputs[input_index] {
+				highest_product = input_index
+			}
+		}
+
+		// a check to see if the neural_network was correct
+		if highest_product == data[data_index].Position {
+			hits++
+		}
+		if config.CM_Enabled {
+			confusion_matrix[data[data_index].Position][highest_product]++
+		}
+	}
+	return Evaluation{
+		Accuracy         : float64(hits) / float64(len(data)) * 100,
+		Loss             : loss / float64(len(data)),
+		Confusion_Matrix : confusion_matrix,
+	}
+}
+
+//********************************************************************
+// Name:	Csv_Styled_Confusion_Matrix
+// Description: This function takes in a confusion matrix and converts
+//		it into a csv styled string.
+// Return:	A string holding the newly styled Confusion matrix, or
+//		an empty string if there is no matrix.
+//********************************************************************
+
+func Csv_Styled_Confusion_Matrix(matrix [][]int) string {
+	if matrix == nil {
+		return ""
+	}
+	confusion_matrix := "\nConfusion Matrix\n"
+	//Creating the top line of the confusion matrix.
+	for i := 0; i < len(matrix); i++ {
+		confusion_matrix += fmt.Sprintf(" ,%d", i)
+	}
+	confusion_matrix += fmt.Sprintf("\n")
+
+	//generating the left column of the confusion matrix, and each rows count.
+	for i := 0; i < len(matrix); i++ {
+		confusion_matrix += fmt.Sprintf("%d, ", i)
+		for j := 0; j < len(matrix[i]); j++ {
+			confusion_matrix += fmt.Sprintf("%d, ", matrix[i][j])
+		}
+		confusion_matrix += fmt.Sprintf("\n")
+	}
+	return confusion_matrix
+}
+
+//********************************************************************
+// Name:	Csv_Styled_Label_Scores
+// Description: This function takes in the scores of each label from
+//		a multi label test and converts them into a csv styled
+//		string.
+// Return:	A string holding the styled label scores, or an empty
+//		string if there are none.
+//********************************************************************
+
+func Csv_Styled_Label_Scores(label_scores []Label_Score) string {
+	if label_scores == nil {
+		return ""
+	}
+	styled := "\nLabel Scores\nlabel, precision, recall, f1\n"
+	for _, score := range label_scores {
+		styled += fmt.Sprintf("%s, %f, %f, %f\n", score.Label, score.Precision, score.Recall, score.F1)
+	}
+	return styled
+}
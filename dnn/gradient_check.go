@@ -0,0 +1,124 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+)
+
+// Gradient_Check_Tolerance is the largest relative error a gradient
+// check should find when backpropagation is right.
+const Gradient_Check_Tolerance = 1e-5
+
+//********************************************************************
+// Name:	Gradient_Check_Result
+// Description: How closely backpropagation matched the finite
+//		difference gradient. Layer_Errors holds the largest
+//		relative error found in each layer of weights, and the
+//		rest describe the single worst weight.
+//********************************************************************
+
+type Gradient_Check_Result struct {
+	Max_Relative_Error      float64       `json:"max_relative_error"`
+	Layer_Errors            []float64     `json:"layer_errors"`
+	Worst_Layer             int           `json:"worst_layer"`
+	Worst_Node              int           `json:"worst_node"`
+	Worst_Weight            int           `json:"worst_weight"`
+	Analytic                float64       `json:"analytic"`
+	Numerical               float64       `json:"numerical"`
+}
+
+//********************************************************************
+// Name:	Passed
+// Description: This function checks the result against a tolerance.
+// Return:	returns true if every weight's relative error is
+//		within the tolerance.
+//********************************************************************
+
+func (result Gradient_Check_Result) Passed(tolerance float64) bool {
+	return result.Max_Relative_Error <= tolerance
+}
+
+//********************************************************************
+// Name:	String
+// Description: This function formats the result for the log.
+// Return:	A string describing the largest errors found.
+//********************************************************************
+
+func (result Gradient_Check_Result) String() string {
+	check := fmt.Sprintf("The largest relative error is %g, at layer %d node %d weight %d where backpropagation gave %g and the finite difference gave %g.",
+		result.Max_Relative_Error, result.Worst_Layer, result.Worst_Node, result.Worst_Weight, result.Analytic, result.Numerical)
+	for layer_index, layer_error := range result.Layer_Errors {
+		check += fmt.Sprintf("\nlayer %d, %g", layer_index, layer_error)
+	}
+	return check
+}
+
+//********************************************************************
+// Name:	Gradient_Check
+// Description: This function compares the gradient backpropagation
+//		finds for the average loss over the data against a
+//		central finite difference, nudging each weight up and
+//		down by epsilon. Dropout and the penalties are left
+//		out, so only the loss function and the network itself
+//		are checked. The data is run through as one batch, so
+//		batch normalization uses the data's own statistics,
+//		and its scale and shift are checked like any other
+//		layer of weights. It runs the whole data set twice for
+//		every weight, so it is only meant for small networks.
+// Return:	returns how closely the two gradients matched.
+//********************************************************************
+
+func (network *Network) Gradient_Check(config *Config, data []Input, epsilon float64) Gradient_Check_Result {
+	loss := config.new_loss()
+	batch := network.new_gradient()
+	layers := network.layers()
+	network.bind(layers, network.Weights, batch.Weights)
+	backpropagate(layers, data, nil, batch, loss)
+
+	values := make([][]float64, len(data))
+	for s, data_point := range data {
+		values[s] = data_point.Values[1:]
+	}
+	average_loss := func() float64 {
+		total := 0.0
+		for s, outputs := range forward(layers, values, true, nil) {
+			total += loss.loss(outputs, data[s].Target)
+		}
+		return total / float64(len(data))
+	}
+
+	result := Gradient_Check_Result{Layer_Errors: make([]float64, len(network.Weights))}
+	// check nudges one setting and compares the two gradients for it
+	check := func(value *float64, analytic float64, layer_index int, k int, j int) {
+		setting := *value
+		*value = setting + epsilon
+		higher := average_loss()
+		*value = setting - epsilon
+		lower := average_loss()
+		*value = setting
+
+		numerical := (higher - lower) / (2 * epsilon)
+		analytic /= float64(batch.count)
+		// the floor keeps rounding errors in gradients that are both
+		// about 0 from looking like large relative errors
+		relative_error := math.Abs(analytic - numerical) / math.Max(math.Abs(analytic) + math.Abs(numerical), 1e-6)
+		result.Layer_Errors[layer_index] = math.Max(result.Layer_Errors[layer_index], relative_error)
+		if relative_error > result.Max_Relative_Error {
+			result.Max_Relative_Error = relative_error
+			result.Worst_Layer = layer_index
+			result.Worst_Node = k
+			result.Worst_Weight = j
+			result.Analytic = analytic
+			result.Numerical = numerical
+		}
+	}
+
+	for layer_index := 0; layer_index < len(network.Weights); layer_index++ {
+		for k := 0; k < len(network.Weights[layer_index]); k++ {
+			for j := 0; j < len(network.Weights[layer_index][k]); j++ {
+				check(&network.Weights[layer_index][k][j], batch.Weights[layer_index][k][j], layer_index, k, j)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package dnn
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+//********************************************************************
+// Name:	gradient_check_config
+// Description: This function builds the config of a small network for
+//		a gradient check, with 3 input values and 3 outputs.
+// Return:	returns the config.
+//********************************************************************
+
+func gradient_check_config(hidden_count []int, hidden_activation string, output_activation string, loss string) *Config {
+	config := New_Config()
+	config.Input_Count = 4
+	config.Hidden_Count = hidden_count
+	config.Hidden_Layers = len(hidden_count)
+	config.Output_Count = 3
+	config.Output_Activation = output_activation
+	config.Loss = loss
+	config.Random_Seed = 11
+	for i := 0; i < len(hidden_count); i++ {
+		config.Hidden_Activations = append(config.Hidden_Activations, hidden_activation)
+	}
+	return config
+}
+
+//********************************************************************
+// Name:	gradient_check_network
+// Description: This function builds a network for a gradient check
+//		with weights large enough that every layer has a
+//		gradient worth measuring, along with a few random
+//		inputs and targets to check it on.
+// Return:	returns the config, the network and the data.
+//********************************************************************
+
+func gradient_check_network(hidden_count []int, hidden_activation string, output_activation string,
+	loss string) (*Config, *Network, []Input) {
+	config := gradient_check_config(hidden_count, hidden_activation, output_activation, loss)
+	network, data := gradient_check_setup(config)
+	return config, network, data
+}
+
+//********************************************************************
+// Name:	gradient_check_layers
+// Description: This function builds a network for a gradient check
+//		from declared layers, for inputs of the given shape.
+//		A nil shape keeps the 3 flat input values.
+// Return:	returns the config, the network and the data.
+//********************************************************************
+
+func gradient_check_layers(input_shape []int, layers []Layer_Spec, output_activation string,
+	loss string) (*Config, *Network, []Input) {
+	config := gradient_check_config(nil, "", output_activation, loss)
+	if input_shape != nil {
+		config.Input_Count = shape_size(input_shape) + 1
+		config.Input_Shape = input_shape
+	}
+	config.Layers = layers
+	network, data := gradient_check_setup(config)
+	return config, network, data
+}
+
+//********************************************************************
+// Name:	gradient_check_setup
+// Description: This function builds the config's network with random
+//		weights, and a few random inputs with targets.
+// Return:	returns the network and the data.
+//********************************************************************
+
+func gradient_check_setup(config *Config) (*Network, []Input) {
+	network := New_Network(config, true)
+	generator := New_Random(config.Random_Seed + 1).generator()
+	randomize_weights(network, generator)
+
+	var data []Input
+	targets := config.target_matrix()
+	for i := 0; i < 5; i++ {
+		data_point := Input{Values: []float64{1}, Position: i % config.Output_Count}
+		for j := 1; j < config.Input_Count; j++ {
+			data_point.Values = append(data_point.Values, generator.Float64())
+		}
+		data_point.Target = targets[data_point.Position]
+		data = append(data, data_point)
+	}
+	return network, data
+}
+
+//********************************************************************
+// Name:	randomize_weights
+// Description: This function draws every weight between -1 and 1.
+//		Batch norm layers get a shift between -.5 and .5 and a
+//		scale between .5 and 1.5 instead, so their gradients
+//		are checked somewhere typical.
+//********************************************************************
+
+func randomize_weights(network *Network, generator *rand.Rand) {
+	for _, layer := range network.layers() {
+		_, normalized := layer.(*batch_norm_layer)
+		for _, weights := range layer.parameters() {
+			for k := range weights {
+				if normalized {
+					weights[k][0] = generator.Float64() - .5
+					weights[k][1] = generator.Float64() + .5
+					continue
+				}
+				for j := range weights[k] {
+					weights[k][j] = generator.Float64() * 2 - 1
+				}
+			}
+		}
+	}
+}
+
+func TestGradientCheckActivations(t *testing.T) {
+	activations := []string{Sigmoid, Tanh, ReLU, Leaky_ReLU, ELU, GELU, Softplus, Linear}
+	architectures := [][]int{{5}, {4, 4}, {3, 3, 3}}
+	for _, activation := range activations {
+		for _, hidden_count := range architectures {
+			t.Run(fmt.Sprintf("%s %v", activation, hidden_count), func(t *testing.T) {
+				config, network, data := gradient_check_network(hidden_count, activation, Sigmoid, Squared_Error_Loss)
+				result := network.Gradient_Check(config, data, 1e-5)
+				if !result.Passed(Gradient_Check_Tolerance) {
+					t.Error(result)
+				}
+			})
+		}
+	}
+}
+
+func TestGradientCheckLosses(t *testing.T) {
+	tests := []struct {
+		output_activation string
+		loss              string
+		targets           [][]float64
+	}{
+		{Sigmoid, Squared_Error_Loss, nil},
+		{Sigmoid, Binary_Cross_Entropy, nil},
+		{Sigmoid, Binary_Cross_Entropy, [][]float64{{.9, .1, .1}, {.1, .9, .1}, {.1, .1, .9}}},
+		{Softmax, Categorical_Cross_Entropy, nil},
+		{Softmax, Categorical_Cross_Entropy, [][]float64{{.9, .1, .1}, {.1, .9, .1}, {.1, .1, .9}}},
+		{Softmax, Squared_Error_Loss, nil},
+		{Linear, Huber_Loss, nil},
+		{Tanh, Squared_Error_Loss, nil},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s %v", test.output_activation, test.loss, test.targets), func(t *testing.T) {
+			config, network, data := gradient_check_network([]int{4, 4}, Tanh, test.output_activation, test.loss)
+			if test.targets != nil {
+				// targets that do not sum to 1, as a config without default targets can give
+				for i := range data {
+					data[i].Target = test.targets[data[i].Position]
+				}
+			}
+			result := network.Gradient_Check(config, data, 1e-5)
+			if !result.Passed(Gradient_Check_Tolerance) {
+				t.Error(result)
+			}
+		})
+	}
+}
+
+func TestGradientCheckBatchNorm(t *testing.T) {
+	tests := []struct {
+		hidden_count      []int
+		layers            []bool
+		activation        string
+	}{
+		{[]int{5}, []bool{true}, Sigmoid},
+		{[]int{4, 3}, []bool{true, true}, Tanh},
+		{[]int{3, 4, 3}, []bool{false, true, false}, GELU},
+		{[]int{6, 2}, []bool{true, false}, Softplus},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %v %v", test.activation, test.hidden_count, test.layers), func(t *testing.T) {
+			config := gradient_check_config(test.hidden_count, test.activation, Softmax, Categorical_Cross_Entropy)
+			config.Batch_Normalization.Layers = test.layers
+			network, data := gradient_check_setup(config)
+			// a bias feeding a normalized layer has no gradient at all, and a
+			// larger nudge keeps the finite difference's rounding error under the floor
+			result := network.Gradient_Check(config, data, 1e-4)
+			if !result.Passed(Gradient_Check_Tolerance) {
+				t.Error(result)
+			}
+		})
+	}
+}
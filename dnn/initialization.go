@@ -0,0 +1,229 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// The ways the starting weights of a layer can be drawn.
+const (
+	Uniform_Initializer         = "uniform"
+	Xavier_Uniform_Initializer  = "xavier_uniform"
+	Xavier_Normal_Initializer   = "xavier_normal"
+	He_Uniform_Initializer      = "he_uniform"
+	He_Normal_Initializer       = "he_normal"
+	LeCun_Uniform_Initializer   = "lecun_uniform"
+	LeCun_Normal_Initializer    = "lecun_normal"
+	Orthogonal_Initializer      = "orthogonal"
+)
+
+//********************************************************************
+// Name:	Initialization
+// Description: How the starting weights are drawn. Weights holds an
+//		initializer for each dense layer of weights when the
+//		layers are built from the hidden layer settings, and
+//		leaving it out uses uniform for every layer. Uniform
+//		draws every weight, bias included, from -.05 to .05.
+//		The others scale their spread by the number of nodes
+//		feeding the layer (fan in) and the number it feeds
+//		(fan out), then multiply it by Gain, and start every
+//		bias weight at Bias. Xavier suits sigmoid and tanh, He
+//		suits ReLU and its relatives, LeCun suits ELU, and
+//		orthogonal makes the rows or columns of each layer
+//		orthonormal.
+//********************************************************************
+
+type Initialization struct {
+	Weights                 []string      `json:"weights"`
+	Gain                    float64       `json:"gain"`
+	Bias                    float64       `json:"bias"`
+}
+
+//********************************************************************
+// Name:	Is_Initializer
+// Description: This function checks if a name is a known initializer.
+// Return:	returns true if the initializer is known.
+//********************************************************************
+
+func Is_Initializer(name string) bool {
+	switch name {
+	case Uniform_Initializer, Xavier_Uniform_Initializer, Xavier_Normal_Initializer,
+		He_Uniform_Initializer, He_Normal_Initializer, LeCun_Uniform_Initializer,
+		LeCun_Normal_Initializer, Orthogonal_Initializer:
+		return true
+	}
+	return false
+}
+
+//********************************************************************
+// Name:	error_check
+// Description: This function checks the initialization settings
+//		against the number of layers of weights.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (initialization *Initialization) error_check(hidden_layers int) error {
+	if initialization.Gain <= 0 {
+		return fmt.Errorf("The initialization gain must be greater than 0.")
+	}
+	if initialization.Weights == nil {
+		return nil
+	}
+	if hidden_layers > 0 && len(initialization.Weights) != hidden_layers + 1 {
+		return fmt.Errorf("There must be one initializer for each layer of weights, which is %d.", hidden_layers + 1)
+	}
+	for _, initializer := range initialization.Weights {
+		if !Is_Initializer(initializer) {
+			return fmt.Errorf("%s is not a known initializer.", initializer)
+		}
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	initializers
+// Description: This function lists the initializer of each layer of
+//		weights, using uniform for layers it does not give one
+//		for.
+// Return:	returns an array with one initializer for each layer.
+//********************************************************************
+
+func (initialization *Initialization) initializers(layer_count int) []string {
+	var initializers []string
+	for layer_index := 0; layer_index < layer_count; layer_index++ {
+		if layer_index < len(initialization.Weights) {
+			initializers = append(initializers, initialization.Weights[layer_index])
+		} else {
+			initializers = append(initializers, Uniform_Initializer)
+		}
+	}
+	return initializers
+}
+
+//********************************************************************
+// Name:	draw
+// Description: This function draws the starting weights of one layer
+//		with an initializer. Each row holds the weights
+//		feeding one node, with the bias in column 0.
+//********************************************************************
+
+func (initialization *Initialization) draw(layer [][]float64, initializer string, generator *rand.Rand) {
+	rows, columns := len(layer), len(layer[0])
+	fan_in, fan_out := float64(columns - 1), float64(rows)
+	gain := initialization.Gain
+
+	switch initializer {
+	case Uniform_Initializer:
+		for k := 0; k < rows; k++ {
+			for j := 0; j < columns; j++ {
+				layer[k][j] = (generator.Float64() / 10) - .05
+			}
+		}
+		return
+	case Xavier_Uniform_Initializer:
+		draw_uniform(layer, gain * math.Sqrt(6 / (fan_in + fan_out)), generator)
+	case Xavier_Normal_Initializer:
+		draw_normal(layer, gain * math.Sqrt(2 / (fan_in + fan_out)), generator)
+	case He_Uniform_Initializer:
+		draw_uniform(layer, gain * math.Sqrt(6 / fan_in), generator)
+	case He_Normal_Initializer:
+		draw_normal(layer, gain * math.Sqrt(2 / fan_in), generator)
+	case LeCun_Uniform_Initializer:
+		draw_uniform(layer, gain * math.Sqrt(3 / fan_in), generator)
+	case LeCun_Normal_Initializer:
+		draw_normal(layer, gain * math.Sqrt(1 / fan_in), generator)
+	case Orthogonal_Initializer:
+		draw_orthogonal(layer, gain, generator)
+	}
+	for k := 0; k < rows; k++ {
+		layer[k][0] = initialization.Bias
+	}
+}
+
+//********************************************************************
+// Name:	draw_uniform
+// Description: This function draws every weight of a layer but the
+//		bias from -limit to limit.
+//********************************************************************
+
+func draw_uniform(layer [][]float64, limit float64, generator *rand.Rand) {
+	for k := 0; k < len(layer); k++ {
+		for j := 1; j < len(layer[k]); j++ {
+			layer[k][j] = (generator.Float64() * 2 - 1) * limit
+		}
+	}
+}
+
+//********************************************************************
+// Name:	draw_normal
+// Description: This function draws every weight of a layer but the
+//		bias from a normal distribution around 0.
+//********************************************************************
+
+func draw_normal(layer [][]float64, deviation float64, generator *rand.Rand) {
+	for k := 0; k < len(layer); k++ {
+		for j := 1; j < len(layer[k]); j++ {
+			layer[k][j] = generator.NormFloat64() * deviation
+		}
+	}
+}
+
+//********************************************************************
+// Name:	draw_orthogonal
+// Description: This function fills every weight of a layer but the
+//		bias with gain times an orthonormal matrix. Normal
+//		draws are made orthonormal with Gram-Schmidt, along
+//		the rows when there are no more rows than inputs and
+//		along the columns otherwise, since only the shorter
+//		side can be fully orthonormal.
+//********************************************************************
+
+func draw_orthogonal(layer [][]float64, gain float64, generator *rand.Rand) {
+	rows, columns := len(layer), len(layer[0]) - 1
+	by_rows := rows <= columns
+	count, length := columns, rows
+	if by_rows {
+		count, length = rows, columns
+	}
+
+	var vectors [][]float64
+	for len(vectors) < count {
+		vector := make([]float64, length)
+		for i := range vector {
+			vector[i] = generator.NormFloat64()
+		}
+		for _, previous := range vectors {
+			dot := 0.0
+			for i := range vector {
+				dot += vector[i] * previous[i]
+			}
+			for i := range vector {
+				vector[i] -= dot * previous[i]
+			}
+		}
+		norm := 0.0
+		for i := range vector {
+			norm += vector[i] * vector[i]
+		}
+		norm = math.Sqrt(norm)
+		if norm < 1e-10 {
+			// the draw was all but a mix of the vectors before it, so it is drawn again
+			continue
+		}
+		for i := range vector {
+			vector[i] /= norm
+		}
+		vectors = append(vectors, vector)
+	}
+
+	for k := 0; k < rows; k++ {
+		for j := 1; j <= columns; j++ {
+			if by_rows {
+				layer[k][j] = gain * vectors[k][j - 1]
+			} else {
+				layer[k][j] = gain * vectors[j - 1][k]
+			}
+		}
+	}
+}
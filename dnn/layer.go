@@ -0,0 +1,432 @@
+package dnn
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The kinds of layers a network can be built from.
+const (
+	Dense_Layer             = "dense"
+	Activation_Layer        = "activation"
+	Dropout_Layer           = "dropout"
+	Batch_Norm_Layer        = "batch_norm"
+	Conv1D_Layer            = "conv1d"
+	Conv2D_Layer            = "conv2d"
+	Max_Pool2D_Layer        = "max_pool2d"
+	Avg_Pool2D_Layer        = "avg_pool2d"
+	Flatten_Layer           = "flatten"
+	RNN_Layer               = "rnn"
+	LSTM_Layer              = "lstm"
+	GRU_Layer               = "gru"
+)
+
+//********************************************************************
+// Name:	Layer_Spec
+// Description: The description of one layer, as it is declared in the
+//		config and saved in the model. Only the fields the
+//		layer's Type uses are set. Dense layers have Nodes
+//		outputs whose weights are drawn with Initializer,
+//		activation layers run Activation on every value,
+//		dropout layers drop values at Rate while training, and
+//		batch norm layers keep the running mean and variance
+//		of every value along with their Momentum and Epsilon.
+//		Convolution layers slide Filters kernels of
+//		Kernel_Size over their inputs, moving Stride each step
+//		over inputs with Padding zeros added to each side, and
+//		pooling layers take the max or average of each
+//		Pool_Size window. Each of these lists one entry for
+//		each of the layer's spatial dimensions, or a single
+//		entry used for all of them. Recurrent layers have
+//		Nodes nodes and output their state after every
+//		timestep if Return_Sequences is set, and rnn layers
+//		run their state through Activation.
+//********************************************************************
+
+type Layer_Spec struct {
+	Type                    string        `json:"type"`
+	Nodes                   int           `json:"nodes,omitempty"`
+	Activation              string        `json:"activation,omitempty"`
+	Initializer             string        `json:"initializer,omitempty"`
+	Filters                 int           `json:"filters,omitempty"`
+	Kernel_Size             []int         `json:"kernel_size,omitempty"`
+	Stride                  []int         `json:"stride,omitempty"`
+	Padding                 []int         `json:"padding,omitempty"`
+	Pool_Size               []int         `json:"pool_size,omitempty"`
+	Return_Sequences        bool          `json:"return_sequences,omitempty"`
+	Rate                    float64       `json:"rate,omitempty"`
+	Momentum                float64       `json:"momentum,omitempty"`
+	Epsilon                 float64       `json:"epsilon,omitempty"`
+	Running_Mean            []float64     `json:"running_mean,omitempty"`
+	Running_Variance        []float64     `json:"running_variance,omitempty"`
+}
+
+//********************************************************************
+// Name:	Layer
+// Description: One step of a network. Every layer works on a whole
+//		batch at once, with the first index of every array
+//		being the input. forward runs the batch through the
+//		layer, training or not, keeping whatever backward
+//		needs. backward takes the gradient of the loss with
+//		respect to the layer's outputs, adds the gradient of
+//		its parameters onto the ones bound to it, and returns
+//		the gradient with respect to its inputs if inputs is
+//		true. The parameters are 2D matrices that live in the
+//		network's weights, and gradients are shaped the same.
+//		Each input's values are stored flat, and output_shape
+//		gives the shape they stand for, with the channels
+//		last.
+//********************************************************************
+
+type Layer interface {
+	output_shape() []int
+	shapes() [][2]int
+	initialize(parameters [][][]float64, initialization *Initialization, generator *rand.Rand)
+	bind(parameters [][][]float64, gradients [][][]float64)
+	forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64
+	backward(output_gradients [][]float64, inputs bool) [][]float64
+	parameters() [][][]float64
+	gradients() [][][]float64
+	regularized() bool
+}
+
+//********************************************************************
+// Name:	stateful
+// Description: A layer that keeps something besides its parameters
+//		up to date while training, which update does after
+//		every step of the weights.
+//********************************************************************
+
+type stateful interface {
+	update()
+}
+
+//********************************************************************
+// Name:	no_parameters
+// Description: The parts of Layer that layers without parameters all
+//		share.
+//********************************************************************
+
+type no_parameters struct{}
+
+func (no_parameters) shapes() [][2]int { return nil }
+func (no_parameters) initialize(parameters [][][]float64, initialization *Initialization, generator *rand.Rand) {}
+func (no_parameters) bind(parameters [][][]float64, gradients [][][]float64) {}
+func (no_parameters) parameters() [][][]float64 { return nil }
+func (no_parameters) gradients() [][][]float64 { return nil }
+func (no_parameters) regularized() bool { return false }
+
+//********************************************************************
+// Name:	weight_matrix
+// Description: The parts of Layer that layers with a single matrix of
+//		weights all share. The weights are drawn with the
+//		spec's initializer, or uniform if it does not have
+//		one, and are left at 0 without a generator.
+//********************************************************************
+
+type weight_matrix struct {
+	spec                    *Layer_Spec
+	weights                 [][]float64
+	weight_gradients        [][]float64
+}
+
+//********************************************************************
+// Name:	new_weight_matrix
+// Description: This function checks the initializer of a layer with
+//		a single matrix of weights.
+// Return:	returns the shared parts of the layer, or an error if
+//		the initializer is not known.
+//********************************************************************
+
+func new_weight_matrix(spec *Layer_Spec) (weight_matrix, error) {
+	if spec.Initializer != "" && !Is_Initializer(spec.Initializer) {
+		return weight_matrix{}, fmt.Errorf("%s is not a known initializer.", spec.Initializer)
+	}
+	return weight_matrix{spec: spec}, nil
+}
+
+func (matrix *weight_matrix) bind(parameters [][][]float64, gradients [][][]float64) {
+	matrix.weights = parameters[0]
+	if gradients != nil {
+		matrix.weight_gradients = gradients[0]
+	}
+}
+
+func (matrix *weight_matrix) parameters() [][][]float64 {
+	return [][][]float64{matrix.weights}
+}
+
+func (matrix *weight_matrix) gradients() [][][]float64 {
+	return [][][]float64{matrix.weight_gradients}
+}
+
+func (matrix *weight_matrix) regularized() bool {
+	return true
+}
+
+func (matrix *weight_matrix) initialize(parameters [][][]float64, initialization *Initialization, generator *rand.Rand) {
+	if generator == nil {
+		return
+	}
+	initializer := matrix.spec.Initializer
+	if initializer == "" {
+		initializer = Uniform_Initializer
+	}
+	initialization.draw(parameters[0], initializer, generator)
+}
+
+//********************************************************************
+// Name:	shape_size
+// Description: This function counts the values in a shape.
+// Return:	returns the product of the shape's dimensions.
+//********************************************************************
+
+func shape_size(shape []int) int {
+	size := 1
+	for _, dimension := range shape {
+		size *= dimension
+	}
+	return size
+}
+
+//********************************************************************
+// Name:	dimensions
+// Description: This function expands a setting that gives one value
+//		for each spatial dimension, or a single value for all
+//		of them, using fallback when it is left out.
+// Return:	returns one value for each dimension, or an error if
+//		the setting has the wrong number of values.
+//********************************************************************
+
+func dimensions(name string, values []int, count int, fallback int) ([]int, error) {
+	expanded := make([]int, count)
+	for i := range expanded {
+		switch len(values) {
+		case 0:
+			expanded[i] = fallback
+		case 1:
+			expanded[i] = values[0]
+		case count:
+			expanded[i] = values[i]
+		default:
+			return nil, fmt.Errorf("The %s needs 1 or %d values.", name, count)
+		}
+	}
+	return expanded, nil
+}
+
+//********************************************************************
+// Name:	new_layer
+// Description: This function builds a layer from its spec for inputs
+//		of the given shape. Batch norm layers keep their
+//		running statistics in the spec itself, so it is passed
+//		by pointer.
+// Return:	returns the layer, or an error if the spec can not be
+//		built.
+//********************************************************************
+
+func new_layer(spec *Layer_Spec, input_shape []int) (Layer, error) {
+	input_size := shape_size(input_shape)
+	if len(input_shape) == 0 || input_size <= 0 {
+		return nil, fmt.Errorf("A %s layer has no inputs.", spec.Type)
+	}
+	switch spec.Type {
+	case Dense_Layer:
+		if spec.Nodes <= 0 {
+			return nil, fmt.Errorf("A dense layer needs at least 1 node.")
+		}
+		matrix, err := new_weight_matrix(spec)
+		if err != nil {
+			return nil, err
+		}
+		return &dense_layer{weight_matrix: matrix, input_count: input_size}, nil
+	case Activation_Layer:
+		if !Is_Activation(spec.Activation) {
+			return nil, fmt.Errorf("%s is not a known activation function.", spec.Activation)
+		}
+		return &activation_layer{name: spec.Activation, shape: input_shape}, nil
+	case Dropout_Layer:
+		if spec.Rate < 0 || spec.Rate >= 1 {
+			return nil, fmt.Errorf("Each dropout rate must be at least 0 and less than 1.")
+		}
+		return &dropout_layer{rate: spec.Rate, shape: input_shape}, nil
+	case Batch_Norm_Layer:
+		if spec.Momentum < 0 || spec.Momentum >= 1 || spec.Epsilon <= 0 {
+			return nil, fmt.Errorf("A batch norm layer needs a momentum of at least 0 and less than 1, and an epsilon greater than 0.")
+		}
+		if spec.Running_Mean == nil && spec.Running_Variance == nil {
+			spec.Running_Mean = make([]float64, input_size)
+			spec.Running_Variance = make([]float64, input_size)
+			for j := range spec.Running_Variance {
+				spec.Running_Variance[j] = 1
+			}
+		}
+		if len(spec.Running_Mean) != input_size || len(spec.Running_Variance) != input_size {
+			return nil, fmt.Errorf("A batch norm layer with %d inputs does not have a running mean and variance for each of them.", input_size)
+		}
+		return &batch_norm_layer{spec: spec, shape: input_shape, size: input_size}, nil
+	case Conv1D_Layer, Conv2D_Layer:
+		return new_conv_layer(spec, input_shape)
+	case Max_Pool2D_Layer, Avg_Pool2D_Layer:
+		return new_pool_layer(spec, input_shape)
+	case Flatten_Layer:
+		return &flatten_layer{size: input_size}, nil
+	case RNN_Layer, LSTM_Layer, GRU_Layer:
+		return new_recurrent_layer(spec, input_shape)
+	}
+	return nil, fmt.Errorf("%s is not a known layer type.", spec.Type)
+}
+
+//********************************************************************
+// Name:	build_layers
+// Description: This function builds every layer from the specs,
+//		feeding each the output shape of the one before it.
+//		input_shape does not count the bias value.
+// Return:	returns the layers, or an error naming the first layer
+//		that could not be built.
+//********************************************************************
+
+func build_layers(specs []Layer_Spec, input_shape []int) ([]Layer, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("The network has no layers.")
+	}
+	var layers []Layer
+	for i := range specs {
+		layer, err := new_layer(&specs[i], input_shape)
+		if err != nil {
+			return nil, fmt.Errorf("Layer %d: %v", i, err)
+		}
+		if layer_activation, ok := layer.(*activation_layer); ok && layer_activation.name == Softmax && i != len(specs) - 1 {
+			return nil, fmt.Errorf("Layer %d: Only the last layer can use softmax.", i)
+		}
+		layers = append(layers, layer)
+		input_shape = layer.output_shape()
+	}
+	return layers, nil
+}
+
+//********************************************************************
+// Name:	copy_layers
+// Description: This function makes a deep copy of some layer specs.
+// Return:	returns the copy.
+//********************************************************************
+
+func copy_layers(specs []Layer_Spec) []Layer_Spec {
+	if specs == nil {
+		return nil
+	}
+	copied := make([]Layer_Spec, len(specs))
+	for i, spec := range specs {
+		copied[i] = spec
+		copied[i].Running_Mean = append([]float64(nil), spec.Running_Mean...)
+		copied[i].Running_Variance = append([]float64(nil), spec.Running_Variance...)
+	}
+	return copied
+}
+
+//********************************************************************
+// Name:	layer_specs
+// Description: This function lists the layers the config describes.
+//		If the config declares its layers they are used as
+//		they are, with batch norm layers that leave out their
+//		momentum or epsilon taking them from the batch
+//		normalization settings. Otherwise the layers are built
+//		from the hidden node counts the way they always have
+//		been, as a dense layer for each hidden layer followed
+//		by its batch normalization, activation and dropout,
+//		then a dense output layer and its activation.
+// Return:	returns an array of layer specs.
+//********************************************************************
+
+func (config *Config) layer_specs() []Layer_Spec {
+	normalization := config.Batch_Normalization
+	if config.Layers != nil {
+		specs := copy_layers(config.Layers)
+		for i := range specs {
+			if specs[i].Type == Batch_Norm_Layer {
+				if specs[i].Momentum == 0 {
+					specs[i].Momentum = normalization.Momentum
+				}
+				if specs[i].Epsilon == 0 {
+					specs[i].Epsilon = normalization.Epsilon
+				}
+			}
+		}
+		return specs
+	}
+
+	var specs []Layer_Spec
+	activations := config.activations()
+	initializers := config.Initialization.initializers(config.Hidden_Layers + 1)
+	for i := 0; i < config.Hidden_Layers && i < len(config.Hidden_Count); i++ {
+		specs = append(specs, Layer_Spec{Type: Dense_Layer, Nodes: config.Hidden_Count[i], Initializer: initializers[i]})
+		if i < len(normalization.Layers) && normalization.Layers[i] {
+			specs = append(specs, Layer_Spec{Type: Batch_Norm_Layer, Momentum: normalization.Momentum, Epsilon: normalization.Epsilon})
+		}
+		specs = append(specs, Layer_Spec{Type: Activation_Layer, Activation: activations[i]})
+		if i < len(config.Dropout_Rates) && config.Dropout_Rates[i] > 0 {
+			specs = append(specs, Layer_Spec{Type: Dropout_Layer, Rate: config.Dropout_Rates[i]})
+		}
+	}
+	specs = append(specs, Layer_Spec{Type: Dense_Layer, Nodes: config.Output_Count, Initializer: initializers[len(initializers) - 1]})
+	return append(specs, Layer_Spec{Type: Activation_Layer, Activation: activations[len(activations) - 1]})
+}
+
+//********************************************************************
+// Name:	layers_error_check
+// Description: This function checks the layers the config declares.
+//		The settings that describe the layers the old way can
+//		not be mixed with them.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (config *Config) layers_error_check() error {
+	if config.Hidden_Layers != 0 || config.Hidden_Count != nil || config.Hidden_Activations != nil ||
+		config.Dropout_Rates != nil || config.Batch_Normalization.Layers != nil || config.Initialization.Weights != nil {
+		return fmt.Errorf("The hidden layer settings, dropout rates, batch normalization layers and initialization weights can not be used along with layers, add them to the layers instead.")
+	}
+	layers, err := build_layers(config.layer_specs(), config.input_shape())
+	if err != nil {
+		return err
+	}
+	if output_size := shape_size(layers[len(layers) - 1].output_shape()); output_size != config.Output_Count {
+		return fmt.Errorf("The last layer has %d outputs, but there are %d output nodes.",
+			output_size, config.Output_Count)
+	}
+	weighted := false
+	for _, layer := range layers {
+		weighted = weighted || len(layer.shapes()) > 0
+		if _, ok := layer.(*batch_norm_layer); ok && config.Batch_Size < 2 {
+			return fmt.Errorf("Batch normalization needs a batch size of at least 2.")
+		}
+	}
+	if !weighted {
+		return fmt.Errorf("The network needs at least one layer with weights.")
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	parameter_count
+// Description: This function counts the matrices of weights the
+//		config's layers have, which is what the frozen layers,
+//		max norms and optimizer tensors are numbered by.
+// Return:	returns the number of matrices, or 0 if the config
+//		does not describe the layers yet or they can not be
+//		built.
+//********************************************************************
+
+func (config *Config) parameter_count() int {
+	if config.Layers == nil && config.Hidden_Layers == 0 {
+		return 0
+	}
+	layers, err := build_layers(config.layer_specs(), config.input_shape())
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, layer := range layers {
+		count += len(layer.shapes())
+	}
+	return count
+}
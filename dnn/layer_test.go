@@ -0,0 +1,160 @@
+package dnn
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLayersMatchSettings(t *testing.T) {
+	settings, _, data := gradient_check_network([]int{5, 4}, ReLU, Softmax, Categorical_Cross_Entropy)
+	settings.Batch_Size = 2
+	settings.Epoch_Count = 2
+	settings.Dropout_Rates = []float64{.2, 0}
+	settings.Batch_Normalization.Layers = []bool{true, false}
+	settings.Initialization.Weights = []string{He_Normal_Initializer, Xavier_Uniform_Initializer, Uniform_Initializer}
+
+	declared := *settings
+	declared.Hidden_Layers = 0
+	declared.Hidden_Count = nil
+	declared.Hidden_Activations = nil
+	declared.Dropout_Rates = nil
+	declared.Batch_Normalization.Layers = nil
+	declared.Initialization.Weights = nil
+	declared.Layers = []Layer_Spec{
+		{Type: Dense_Layer, Nodes: 5, Initializer: He_Normal_Initializer},
+		{Type: Batch_Norm_Layer},
+		{Type: Activation_Layer, Activation: ReLU},
+		{Type: Dropout_Layer, Rate: .2},
+		{Type: Dense_Layer, Nodes: 4, Initializer: Xavier_Uniform_Initializer},
+		{Type: Activation_Layer, Activation: ReLU},
+		{Type: Dense_Layer, Nodes: 3},
+		{Type: Activation_Layer, Activation: Softmax},
+	}
+	if err := declared.layers_error_check(); err != nil {
+		t.Fatal(err)
+	}
+
+	networks := []*Network{New_Network(settings, true), New_Network(&declared, true)}
+	if describe_layers(networks[0].Layers) != describe_layers(networks[1].Layers) {
+		t.Fatalf("The settings built the layers %s, but the same layers declared built %s.",
+			describe_layers(networks[0].Layers), describe_layers(networks[1].Layers))
+	}
+	if fmt.Sprint(networks[0].Weights) != fmt.Sprint(networks[1].Weights) {
+		t.Fatal("The declared layers did not draw the same starting weights as the settings.")
+	}
+	networks[0].Train(settings, data, nil)
+	networks[1].Train(&declared, data, nil)
+	if fmt.Sprint(networks[0].Weights) != fmt.Sprint(networks[1].Weights) {
+		t.Error("Training the declared layers did not give the same weights as training the settings.")
+	}
+}
+
+func TestGradientCheckLayers(t *testing.T) {
+	tests := [][]Layer_Spec{
+		{
+			{Type: Dense_Layer, Nodes: 4},
+			{Type: Activation_Layer, Activation: Tanh},
+			{Type: Batch_Norm_Layer},
+			{Type: Dense_Layer, Nodes: 3},
+		},
+		{
+			{Type: Activation_Layer, Activation: Tanh},
+			{Type: Dense_Layer, Nodes: 5},
+			{Type: Dense_Layer, Nodes: 3},
+			{Type: Activation_Layer, Activation: ELU},
+		},
+	}
+	for _, layers := range tests {
+		t.Run(describe_layers(layers), func(t *testing.T) {
+			config, network, data := gradient_check_layers(nil, layers, Linear, Huber_Loss)
+			result := network.Gradient_Check(config, data, 1e-5)
+			if !result.Passed(Gradient_Check_Tolerance) {
+				t.Error(result)
+			}
+		})
+	}
+}
+
+func TestGradientCheckConvolution(t *testing.T) {
+	tests := []struct {
+		shape                   []int
+		layers                  []Layer_Spec
+	}{
+		{[]int{4, 4, 2}, []Layer_Spec{
+			{Type: Conv2D_Layer, Filters: 3, Kernel_Size: []int{2}, Padding: []int{1}},
+			{Type: Activation_Layer, Activation: Tanh},
+			{Type: Max_Pool2D_Layer, Pool_Size: []int{2}},
+			{Type: Flatten_Layer},
+			{Type: Dense_Layer, Nodes: 3},
+		}},
+		{[]int{5, 4, 1}, []Layer_Spec{
+			{Type: Conv2D_Layer, Filters: 2, Kernel_Size: []int{3, 2}, Stride: []int{2, 1}},
+			{Type: Avg_Pool2D_Layer, Pool_Size: []int{1, 2}, Stride: []int{1}},
+			{Type: Activation_Layer, Activation: ELU},
+			{Type: Flatten_Layer},
+			{Type: Dense_Layer, Nodes: 3},
+		}},
+		{[]int{6, 2}, []Layer_Spec{
+			{Type: Conv1D_Layer, Filters: 3, Kernel_Size: []int{3}, Stride: []int{2}, Padding: []int{1}},
+			{Type: Activation_Layer, Activation: Tanh},
+			{Type: Conv1D_Layer, Filters: 2, Kernel_Size: []int{2}},
+			{Type: Flatten_Layer},
+			{Type: Dense_Layer, Nodes: 3},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(describe_layers(test.layers), func(t *testing.T) {
+			layers := append(test.layers, Layer_Spec{Type: Activation_Layer, Activation: Softmax})
+			config, network, data := gradient_check_layers(test.shape, layers, Softmax, Categorical_Cross_Entropy)
+			result := network.Gradient_Check(config, data, 1e-5)
+			if !result.Passed(Gradient_Check_Tolerance) {
+				t.Error(result)
+			}
+		})
+	}
+}
+
+func TestGradientCheckRecurrent(t *testing.T) {
+	tests := [][]Layer_Spec{
+		{{Type: RNN_Layer, Nodes: 3}},
+		{{Type: RNN_Layer, Nodes: 2, Activation: ELU, Return_Sequences: true}, {Type: Flatten_Layer}},
+		{{Type: LSTM_Layer, Nodes: 3}},
+		{{Type: LSTM_Layer, Nodes: 2, Return_Sequences: true}, {Type: GRU_Layer, Nodes: 3}},
+		{{Type: GRU_Layer, Nodes: 2, Return_Sequences: true}, {Type: Dense_Layer, Nodes: 4}, {Type: Activation_Layer, Activation: Tanh}},
+	}
+	for _, layers := range tests {
+		t.Run(describe_layers(layers), func(t *testing.T) {
+			layers = append(layers, Layer_Spec{Type: Dense_Layer, Nodes: 3}, Layer_Spec{Type: Activation_Layer, Activation: Softmax})
+			config, network, data := gradient_check_layers([]int{4, 2}, layers, Softmax, Categorical_Cross_Entropy)
+			result := network.Gradient_Check(config, data, 1e-5)
+			if !result.Passed(Gradient_Check_Tolerance) {
+				t.Error(result)
+			}
+		})
+	}
+}
+
+func TestLayersErrorCheckFirst(t *testing.T) {
+	config, _, _ := gradient_check_layers(nil, []Layer_Spec{{Type: Dense_Layer, Nodes: 3},
+		{Type: Activation_Layer, Activation: Softmax}}, Softmax, Categorical_Cross_Entropy)
+	config.Data_File = "data.csv"
+	config.Neural_Network_File = "network.json"
+	config.Max = 1
+	if err := config.Error_Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Hidden_Layers = 2
+	config.Dropout_Rates = []float64{1.5}
+	config.Initialization.Weights = []string{"unknown"}
+	config.Batch_Normalization.Layers = []bool{true}
+	config.Batch_Size = 1
+	err := config.Error_Check()
+	if err == nil {
+		t.Fatal("Hidden layer settings were allowed along with layers.")
+	}
+	if !strings.Contains(err.Error(), "1. The hidden layer settings") || strings.Contains(err.Error(), "2.") {
+		t.Errorf("Hidden layer settings along with layers should give one error about them, but gave:\n%v", err)
+	}
+}
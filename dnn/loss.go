@@ -0,0 +1,334 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+)
+
+// The loss functions a network can be trained with. An empty loss
+// uses categorical cross entropy for softmax outputs, binary cross
+// entropy for multi label sigmoid outputs and half the squared error
+// for everything else.
+const (
+	Squared_Error_Loss      = "squared_error"
+	MAE_Loss                = "mae"
+	Huber_Loss              = "huber"
+	Binary_Cross_Entropy    = "binary_cross_entropy"
+	Categorical_Cross_Entropy = "categorical_cross_entropy"
+	Hinge_Loss              = "hinge"
+)
+
+// smallest_probability keeps the cross entropy losses from taking the
+// log of 0.
+const smallest_probability = 1e-15
+
+//********************************************************************
+// Name:	loss_function
+// Description: A way of measuring how far the outputs are from the
+//		targets. loss gives the error for one input, and
+//		gradient gives its derivative with respect to each
+//		output.
+//********************************************************************
+
+type loss_function interface {
+	loss(outputs []float64, targets []float64) float64
+	gradient(outputs []float64, targets []float64) []float64
+}
+
+type squared_error_loss struct{}
+
+type mae_loss struct{}
+
+type huber_loss struct {
+	delta                   float64
+}
+
+type binary_cross_entropy_loss struct{}
+
+type categorical_cross_entropy_loss struct{}
+
+type hinge_loss struct{}
+
+//********************************************************************
+// Name:	loss_name
+// Description: This function finds the loss function the config asks
+//		for, filling in the default when it is empty.
+// Return:	returns the name of the loss function.
+//********************************************************************
+
+func (config *Config) loss_name() string {
+	if config.Loss != "" {
+		return config.Loss
+	}
+	if config.output_activation() == Softmax {
+		return Categorical_Cross_Entropy
+	}
+	if config.Task == Multi_Label_Task && config.output_activation() == Sigmoid {
+		return Binary_Cross_Entropy
+	}
+	return Squared_Error_Loss
+}
+
+//********************************************************************
+// Name:	Is_Loss
+// Description: This function checks if a name is a known loss
+//		function.
+// Return:	returns true if the loss function exists.
+//********************************************************************
+
+func Is_Loss(name string) bool {
+	switch name {
+	case Squared_Error_Loss, MAE_Loss, Huber_Loss, Binary_Cross_Entropy, Categorical_Cross_Entropy, Hinge_Loss:
+		return true
+	}
+	return false
+}
+
+//********************************************************************
+// Name:	new_loss
+// Description: This function builds the loss function the config
+//		asks for.
+// Return:	returns the loss function.
+//********************************************************************
+
+func (config *Config) new_loss() loss_function {
+	switch config.loss_name() {
+	case MAE_Loss:
+		return mae_loss{}
+	case Huber_Loss:
+		return huber_loss{delta: config.Huber_Delta}
+	case Binary_Cross_Entropy:
+		return binary_cross_entropy_loss{}
+	case Categorical_Cross_Entropy:
+		return categorical_cross_entropy_loss{}
+	case Hinge_Loss:
+		return hinge_loss{}
+	}
+	return squared_error_loss{}
+}
+
+//********************************************************************
+// Name:	output_error_terms
+// Description: This function works out the error term of each output
+//		node, the negative derivative of the loss with respect
+//		to the dot product feeding the node. Softmax mixes
+//		every output together, so its error terms take the
+//		whole layer into account. Sigmoid with binary cross
+//		entropy simplifies down to target - output, and
+//		softmax with categorical cross entropy down to target
+//		- output times the sum of the targets, which is just
+//		target - output when the targets sum to 1. These are
+//		used directly so a confident wrong output can not
+//		divide by 0.
+// Return:	returns an array with the error term of each output.
+//********************************************************************
+
+func output_error_terms(loss loss_function, activation string, dot_products []float64, outputs []float64, targets []float64) []float64 {
+	error_terms := make([]float64, len(outputs))
+	_, categorical := loss.(categorical_cross_entropy_loss)
+	_, binary := loss.(binary_cross_entropy_loss)
+	if activation == Softmax && categorical {
+		total := 0.0
+		for k := 0; k < len(targets); k++ {
+			total += targets[k]
+		}
+		for k := 0; k < len(outputs); k++ {
+			error_terms[k] = targets[k] - outputs[k] * total
+		}
+		return error_terms
+	}
+	if activation == Sigmoid && binary {
+		for k := 0; k < len(outputs); k++ {
+			error_terms[k] = targets[k] - outputs[k]
+		}
+		return error_terms
+	}
+
+	gradient := loss.gradient(outputs, targets)
+	if activation == Softmax {
+		weighted := 0.0
+		for k := 0; k < len(outputs); k++ {
+			weighted += gradient[k] * outputs[k]
+		}
+		for k := 0; k < len(outputs); k++ {
+			error_terms[k] = -outputs[k] * (gradient[k] - weighted)
+		}
+		return error_terms
+	}
+	for k := 0; k < len(outputs); k++ {
+		error_terms[k] = -gradient[k] * derivative(activation, dot_products[k], outputs[k])
+	}
+	return error_terms
+}
+
+//********************************************************************
+// Name:	loss
+// Description: Half the squared error, summed over the outputs.
+//********************************************************************
+
+func (squared_error_loss) loss(outputs []float64, targets []float64) float64 {
+	total := 0.0
+	for k := 0; k < len(outputs); k++ {
+		total += (targets[k] - outputs[k]) * (targets[k] - outputs[k]) / 2
+	}
+	return total
+}
+
+func (squared_error_loss) gradient(outputs []float64, targets []float64) []float64 {
+	gradient := make([]float64, len(outputs))
+	for k := 0; k < len(outputs); k++ {
+		gradient[k] = outputs[k] - targets[k]
+	}
+	return gradient
+}
+
+//********************************************************************
+// Name:	loss
+// Description: The absolute error, summed over the outputs.
+//********************************************************************
+
+func (mae_loss) loss(outputs []float64, targets []float64) float64 {
+	total := 0.0
+	for k := 0; k < len(outputs); k++ {
+		total += math.Abs(targets[k] - outputs[k])
+	}
+	return total
+}
+
+func (mae_loss) gradient(outputs []float64, targets []float64) []float64 {
+	gradient := make([]float64, len(outputs))
+	for k := 0; k < len(outputs); k++ {
+		if outputs[k] > targets[k] {
+			gradient[k] = 1
+		} else if outputs[k] < targets[k] {
+			gradient[k] = -1
+		}
+	}
+	return gradient
+}
+
+//********************************************************************
+// Name:	loss
+// Description: The Huber loss, which is half the squared error for
+//		errors up to delta and grows linearly past it, summed
+//		over the outputs.
+//********************************************************************
+
+func (rule huber_loss) loss(outputs []float64, targets []float64) float64 {
+	total := 0.0
+	for k := 0; k < len(outputs); k++ {
+		difference := math.Abs(outputs[k] - targets[k])
+		if difference <= rule.delta {
+			total += difference * difference / 2
+		} else {
+			total += rule.delta * (difference - rule.delta / 2)
+		}
+	}
+	return total
+}
+
+func (rule huber_loss) gradient(outputs []float64, targets []float64) []float64 {
+	gradient := make([]float64, len(outputs))
+	for k := 0; k < len(outputs); k++ {
+		gradient[k] = math.Max(-rule.delta, math.Min(rule.delta, outputs[k] - targets[k]))
+	}
+	return gradient
+}
+
+//********************************************************************
+// Name:	loss
+// Description: The binary cross entropy, treating each output as the
+//		probability its target is 1, summed over the outputs.
+//********************************************************************
+
+func (binary_cross_entropy_loss) loss(outputs []float64, targets []float64) float64 {
+	total := 0.0
+	for k := 0; k < len(outputs); k++ {
+		output := math.Min(math.Max(outputs[k], smallest_probability), 1 - smallest_probability)
+		total -= targets[k] * math.Log(output) + (1 - targets[k]) * math.Log(1 - output)
+	}
+	return total
+}
+
+func (binary_cross_entropy_loss) gradient(outputs []float64, targets []float64) []float64 {
+	gradient := make([]float64, len(outputs))
+	for k := 0; k < len(outputs); k++ {
+		output := math.Min(math.Max(outputs[k], smallest_probability), 1 - smallest_probability)
+		gradient[k] = (output - targets[k]) / (output * (1 - output))
+	}
+	return gradient
+}
+
+//********************************************************************
+// Name:	loss
+// Description: The categorical cross entropy, treating the outputs
+//		as the probability of each input type.
+//********************************************************************
+
+func (categorical_cross_entropy_loss) loss(outputs []float64, targets []float64) float64 {
+	total := 0.0
+	for k := 0; k < len(outputs); k++ {
+		total -= targets[k] * math.Log(math.Max(outputs[k], smallest_probability))
+	}
+	return total
+}
+
+func (categorical_cross_entropy_loss) gradient(outputs []float64, targets []float64) []float64 {
+	gradient := make([]float64, len(outputs))
+	for k := 0; k < len(outputs); k++ {
+		gradient[k] = -targets[k] / math.Max(outputs[k], smallest_probability)
+	}
+	return gradient
+}
+
+//********************************************************************
+// Name:	loss
+// Description: The hinge loss, summed over the outputs. Targets of at
+//		least .5 count as +1 and the rest as -1, so it works
+//		with the default targets.
+//********************************************************************
+
+func (hinge_loss) loss(outputs []float64, targets []float64) float64 {
+	total := 0.0
+	for k := 0; k < len(outputs); k++ {
+		total += math.Max(0, 1 - hinge_sign(targets[k]) * outputs[k])
+	}
+	return total
+}
+
+func (hinge_loss) gradient(outputs []float64, targets []float64) []float64 {
+	gradient := make([]float64, len(outputs))
+	for k := 0; k < len(outputs); k++ {
+		if hinge_sign(targets[k]) * outputs[k] < 1 {
+			gradient[k] = -hinge_sign(targets[k])
+		}
+	}
+	return gradient
+}
+
+func hinge_sign(target float64) float64 {
+	if target >= .5 {
+		return 1
+	}
+	return -1
+}
+
+//********************************************************************
+// Name:	loss_error_check
+// Description: This function checks the config's loss settings.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (config *Config) loss_error_check() error {
+	if !Is_Loss(config.loss_name()) {
+		return fmt.Errorf("%s is not a known loss function.", config.Loss)
+	}
+	cross_entropy := config.loss_name() == Binary_Cross_Entropy || config.loss_name() == Categorical_Cross_Entropy
+	if cross_entropy && config.output_activation() != Sigmoid && config.output_activation() != Softmax {
+		return fmt.Errorf("The %s loss needs a sigmoid or softmax output layer.", config.loss_name())
+	}
+	if config.loss_name() == Huber_Loss && config.Huber_Delta <= 0 {
+		return fmt.Errorf("The huber loss needs a delta greater than 0.")
+	}
+	return nil
+}
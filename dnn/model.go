@@ -0,0 +1,434 @@
+package dnn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Model_Format names the kind of file this package writes, and
+// Model_Version is bumped whenever the layout of that file changes.
+const (
+	Model_Format            = "deep-neural-network"
+	Model_Version           = 1
+)
+
+// The file formats a model can be saved in.
+const (
+	JSON_File_Format        = "json"
+	Binary_File_Format      = "binary"
+)
+
+//********************************************************************
+// Name:	Normalization
+// Description: The range the raw input values were scaled from
+//		before being passed into the network, and for
+//		regression the range each target was scaled from.
+//********************************************************************
+
+type Normalization struct {
+	Min                     float64       `json:"value_minimum"`
+	Max                     float64       `json:"value_maximum"`
+	Target_Min              []float64     `json:"target_minimum,omitempty"`
+	Target_Max              []float64     `json:"target_maximum,omitempty"`
+}
+
+//********************************************************************
+// Name:	Hyperparameters
+// Description: The settings the network was trained with. These are
+//		only kept as a record of how the model was made.
+//********************************************************************
+
+type Hyperparameters struct {
+	Learning_Rate           float64       `json:"learning_rate"`
+	Learning_Rate_Schedule  Schedule      `json:"learning_rate_schedule"`
+	Momentum                float64       `json:"momentum"`
+	Optimizer               Optimizer     `json:"optimizer"`
+	Epoch_Count             int           `json:"number_of_epochs"`
+	Batch_Size              int           `json:"batch_size"`
+	Random_Seed             int64         `json:"random_seed"`
+	Loss                    string        `json:"loss_function"`
+	Dropout_Rates           []float64     `json:"dropout_rates"`
+	Regularization          Regularization `json:"regularization"`
+	Initialization          Initialization `json:"initialization"`
+	Batch_Normalization     Batch_Normalization `json:"batch_normalization"`
+}
+
+//********************************************************************
+// Name:	Model
+// Description: A trained network together with everything needed to
+//		use it again, so a saved model can be tested without
+//		repeating the training config.
+//********************************************************************
+
+type Model struct {
+	Format                  string          `json:"format"`
+	Version                 int             `json:"version"`
+	Created                 time.Time       `json:"created"`
+	Task                    string          `json:"task,omitempty"`
+	Normalization           Normalization   `json:"normalization"`
+	Class_Labels            []string        `json:"class_labels"`
+	Targets                 [][]float64     `json:"target_values"`
+	Hyperparameters         Hyperparameters `json:"hyperparameters"`
+	Network                 *Network        `json:"network"`
+}
+
+//********************************************************************
+// Name:	New_Model
+// Description: This function wraps a trained network with the
+//		settings from the config it was trained with.
+// Return:	returns a pointer to the new model.
+//********************************************************************
+
+func New_Model(network *Network, config *Config) *Model {
+	return &Model{
+		Format        : Model_Format,
+		Version       : Model_Version,
+		Created       : time.Now().UTC(),
+		Task          : config.Task,
+		Normalization : Normalization{
+			Min        : config.Min,
+			Max        : config.Max,
+			Target_Min : config.Target_Min,
+			Target_Max : config.Target_Max,
+		},
+		Class_Labels  : config.class_labels(),
+		Targets       : config.target_matrix(),
+		Hyperparameters : Hyperparameters{
+			Learning_Rate          : config.Learning_Rate,
+			Learning_Rate_Schedule : config.Learning_Rate_Schedule,
+			Momentum               : config.Momentum,
+			Optimizer              : config.Optimizer,
+			Epoch_Count            : config.Epoch_Count,
+			Batch_Size             : config.Batch_Size,
+			Random_Seed            : config.Random_Seed,
+			Loss                   : config.loss_name(),
+			Dropout_Rates          : config.Dropout_Rates,
+			Regularization         : config.Regularization,
+			Initialization         : config.Initialization,
+			Batch_Normalization    : config.Batch_Normalization,
+		},
+		Network       : network,
+	}
+}
+
+//********************************************************************
+// Name:	File_Format
+// Description: This function works out which format a model file
+//		should be saved in. An empty format picks binary for
+//		files ending in .bin and json for everything else.
+// Return:	returns JSON_File_Format or Binary_File_Format, or an
+//		error if the format is not known.
+//********************************************************************
+
+func File_Format(file_name string, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		if strings.ToLower(filepath.Ext(file_name)) == ".bin" {
+			return Binary_File_Format, nil
+		}
+		return JSON_File_Format, nil
+	case JSON_File_Format:
+		return JSON_File_Format, nil
+	case Binary_File_Format:
+		return Binary_File_Format, nil
+	}
+	return "", fmt.Errorf("%s is not a known model file format, use %s or %s.",
+		format, JSON_File_Format, Binary_File_Format)
+}
+
+//********************************************************************
+// Name:	Save
+// Description: This function writes the model to a file in the given
+//		format. The precision is only used by the binary
+//		format, and is the number of bits used for each
+//		weight.
+// Return:	returns an error if the file could not be written.
+//********************************************************************
+
+func (model *Model) Save(file_name string, format string, precision int) error {
+	format, err := File_Format(file_name, format)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if format == Binary_File_Format {
+		err = model.Write_Binary(&buffer, precision)
+	} else {
+		err = model.Write_JSON(&buffer)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file_name, buffer.Bytes(), 0644)
+}
+
+//********************************************************************
+// Name:	Write_JSON
+// Description: This function writes the model as json.
+// Return:	returns an error if the model could not be written.
+//********************************************************************
+
+func (model *Model) Write_JSON(writer io.Writer) error {
+	model_json, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("Error while marshaling the model into JSON.\n%v", err)
+	}
+	_, err = writer.Write(model_json)
+	return err
+}
+
+//********************************************************************
+// Name:	Load_Model
+// Description: This function reads a model saved by Save in either
+//		format, and checks that it is a version this package
+//		understands.
+// Return:	returns a pointer to the model, or an error if the
+//		file can not be used.
+//********************************************************************
+
+func Load_Model(file_name string) (*Model, error) {
+	file, err := ioutil.ReadFile(file_name)
+	if err != nil {
+		return nil, fmt.Errorf("Error occured when opening %s\n%v", file_name, err)
+	}
+
+	var model *Model
+	if Is_Binary_Model(file) {
+		model, err = Read_Binary_Model(bytes.NewReader(file))
+		if err != nil {
+			return nil, fmt.Errorf("Error occured when reading the model in %s\n%v", file_name, err)
+		}
+	} else {
+		if bytes.HasPrefix(bytes.TrimSpace(file), []byte("[")) {
+			return nil, fmt.Errorf("%s only holds the weights of a network, it was saved by an older version and needs to be retrained.", file_name)
+		}
+		model = &Model{}
+		err = json.Unmarshal(file, model)
+		if err != nil {
+			return nil, fmt.Errorf("Error occured when reading the model in %s\n%v", file_name, err)
+		}
+	}
+
+	err = model.validate(file_name)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+//********************************************************************
+// Name:	validate
+// Description: This function checks that a model read from a file is
+//		a version this package understands and has a network.
+// Return:	returns an error describing what is wrong with the
+//		model.
+//********************************************************************
+
+func (model *Model) validate(file_name string) error {
+	if model.Format != Model_Format {
+		return fmt.Errorf("%s is not a deep neural network model.", file_name)
+	}
+	if model.Version != Model_Version {
+		return fmt.Errorf("%s is model version %d, but only version %d is supported.",
+			file_name, model.Version, Model_Version)
+	}
+	if model.Network == nil || model.Network.Layers == nil {
+		return fmt.Errorf("The network in %s is missing or incomplete.", file_name)
+	}
+	if model.Network.Input_Count < 1 {
+		return fmt.Errorf("The network in %s needs at least one input value.", file_name)
+	}
+	if model.Network.Input_Shape != nil && shape_size(model.Network.Input_Shape) != model.Network.Input_Count - 1 {
+		return fmt.Errorf("The input shape in %s does not hold its input values.", file_name)
+	}
+	layers, err := model.Network.build_layers()
+	if err != nil {
+		return fmt.Errorf("The layers in %s can not be built. %v", file_name, err)
+	}
+	model.Network.describe(layers)
+	err = model.Network.check_weights(model.Network.Weights)
+	if err != nil {
+		return fmt.Errorf("The weights in %s do not match its network. %v", file_name, err)
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	describe_layers
+// Description: This function sums up the shape of some layers, so
+//		two lists of layers can be compared.
+// Return:	returns a string naming each layer and its size.
+//********************************************************************
+
+func describe_layers(specs []Layer_Spec) string {
+	var layers []string
+	for _, spec := range specs {
+		switch spec.Type {
+		case Dense_Layer:
+			layers = append(layers, fmt.Sprintf("%s %d", spec.Type, spec.Nodes))
+		case Activation_Layer:
+			layers = append(layers, spec.Activation)
+		case Dropout_Layer:
+			layers = append(layers, fmt.Sprintf("%s %g", spec.Type, spec.Rate))
+		case Conv1D_Layer, Conv2D_Layer:
+			layers = append(layers, fmt.Sprintf("%s %d %v", spec.Type, spec.Filters, spec.Kernel_Size))
+		case Max_Pool2D_Layer, Avg_Pool2D_Layer:
+			layers = append(layers, fmt.Sprintf("%s %v", spec.Type, spec.Pool_Size))
+		case RNN_Layer, LSTM_Layer, GRU_Layer:
+			layer := fmt.Sprintf("%s %d", spec.Type, spec.Nodes)
+			if spec.Activation != "" {
+				layer += " " + spec.Activation
+			}
+			if spec.Return_Sequences {
+				layer += " sequences"
+			}
+			layers = append(layers, layer)
+		default:
+			layers = append(layers, spec.Type)
+		}
+	}
+	return "[" + strings.Join(layers, ", ") + "]"
+}
+
+//********************************************************************
+// Name:	Match_Config
+// Description: This function checks every network setting that was
+//		given in the config against the model. Settings that
+//		were left out of the config are not checked.
+// Return:	returns an error listing every setting that does not
+//		match the model.
+//********************************************************************
+
+func (model *Model) Match_Config(config *Config) error {
+	error_string := "The config does not match the trained neural network.\n"
+	errors := 0
+	network := model.Network
+
+	if config.Input_Count != 0 && config.Input_Count != network.Input_Count {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has %d input values, but the model has %d.\n",
+			errors, config.Input_Count, network.Input_Count)
+	}
+	if config.Input_Shape != nil && fmt.Sprint(config.Input_Shape) != fmt.Sprint(network.input_shape()) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has input shape %v, but the model has %v.\n",
+			errors, config.Input_Shape, network.input_shape())
+	}
+	if config.Layers != nil && describe_layers(config.Layers) != describe_layers(network.Layers) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has layers %s, but the model has %s.\n",
+			errors, describe_layers(config.Layers), describe_layers(network.Layers))
+	}
+	if (config.Hidden_Layers != 0 || config.Hidden_Count != nil) &&
+		(config.Hidden_Layers != len(network.Hidden_Count) || fmt.Sprint(config.Hidden_Count) != fmt.Sprint(network.Hidden_Count)) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has hidden nodes %v, but the model has %v.\n",
+			errors, config.Hidden_Count, network.Hidden_Count)
+	}
+	if config.Hidden_Activations != nil &&
+		fmt.Sprint(config.Hidden_Activations) != fmt.Sprint(network.hidden_activations()) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has hidden activations %v, but the model has %v.\n",
+			errors, config.Hidden_Activations, network.hidden_activations())
+	}
+	if config.Batch_Normalization.Layers != nil &&
+		fmt.Sprint(config.Batch_Normalization.Layers) != fmt.Sprint(network.batch_norm_layers()) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config batch normalizes hidden layers %v, but the model normalizes %v.\n",
+			errors, config.Batch_Normalization.Layers, network.batch_norm_layers())
+	}
+	if config.Output_Count != 0 && config.Output_Count != network.Output_Count {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has %d output nodes, but the model has %d.\n",
+			errors, config.Output_Count, network.Output_Count)
+	}
+	if (config.Min != 0 || config.Max != 0) &&
+		(config.Min != model.Normalization.Min || config.Max != model.Normalization.Max) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config scales values from %v to %v, but the model was trained on %v to %v.\n",
+			errors, config.Min, config.Max, model.Normalization.Min, model.Normalization.Max)
+	}
+	if config.Task != "" && config.task() != model.task() {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config is for %s, but the model was trained for %s.\n",
+			errors, config.task(), model.task())
+	}
+	if config.Target_Min != nil &&
+		(fmt.Sprint(config.Target_Min) != fmt.Sprint(model.Normalization.Target_Min) ||
+		fmt.Sprint(config.Target_Max) != fmt.Sprint(model.Normalization.Target_Max)) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config scales targets from %v to %v, but the model was trained on %v to %v.\n",
+			errors, config.Target_Min, config.Target_Max, model.Normalization.Target_Min, model.Normalization.Target_Max)
+	}
+	if !config.Default_Target && config.Targets != nil && fmt.Sprint(config.Targets) != fmt.Sprint(model.Targets) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config's target values are not the ones the model was trained on.\n", errors)
+	}
+	if config.Class_Labels != nil && fmt.Sprint(config.Class_Labels) != fmt.Sprint(model.Class_Labels) {
+		errors++
+		error_string += fmt.Sprintf("\t%d. The config has class labels %v, but the model has %v.\n",
+			errors, config.Class_Labels, model.Class_Labels)
+	}
+	if errors > 0 {
+		return fmt.Errorf(error_string)
+	} else {
+		return nil
+	}
+}
+
+//********************************************************************
+// Name:	task
+// Description: This function names the task the model was trained
+//		for.
+// Return:	returns the task, which is classification if the model
+//		does not say.
+//********************************************************************
+
+func (model *Model) task() string {
+	if model.Task == "" {
+		return Classification_Task
+	}
+	return model.Task
+}
+
+//********************************************************************
+// Name:	Apply_Config
+// Description: This function copies the model's network settings into
+//		the config, so the data set is read the same way the
+//		model was trained. The model's loss function is also
+//		used unless the config picks its own. Dropout rates
+//		given in the config replace the network's own, so a
+//		fine tuned network can be dropped out differently.
+//********************************************************************
+
+func (model *Model) Apply_Config(config *Config) {
+	config.Input_Count = model.Network.Input_Count
+	config.Input_Shape = append([]int(nil), model.Network.Input_Shape...)
+	config.Hidden_Count = append([]int(nil), model.Network.Hidden_Count...)
+	config.Hidden_Layers = len(model.Network.Hidden_Count)
+	config.Output_Count = model.Network.Output_Count
+	config.Hidden_Activations = model.Network.hidden_activations()
+	config.Output_Activation = model.Network.output_activation()
+	if config.Dropout_Rates != nil {
+		model.Network.set_dropout(config.Dropout_Rates)
+	}
+	config.Layers = copy_layers(model.Network.Layers)
+	config.Min = model.Normalization.Min
+	config.Max = model.Normalization.Max
+	config.Default_Target = false
+	config.Targets = model.Targets
+	config.Class_Labels = model.Class_Labels
+	config.Task = model.Task
+	config.Target_Min = model.Normalization.Target_Min
+	config.Target_Max = model.Normalization.Target_Max
+	if config.Loss == "" {
+		config.Loss = model.Hyperparameters.Loss
+	}
+}
@@ -0,0 +1,386 @@
+package dnn
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	Network
+// Description: A deep neural network. Layers describes each of its
+//		layers in order, and Weights holds every matrix of
+//		parameters they have in the same order, indexed as
+//		[matrix][row][column]. For a dense layer each row
+//		holds the weights feeding one node, with the bias in
+//		column 0. Input_Count counts the bias value that
+//		starts every input, and Input_Shape is the shape of
+//		the rest of the values, or nil if they are a flat
+//		list. Hidden_Count and Activations sum up the dense
+//		layers, giving the nodes in each hidden dense layer
+//		and the activation function that follows each dense
+//		layer.
+//********************************************************************
+
+type Network struct {
+	Input_Count             int           `json:"number_of_input_values"`
+	Input_Shape             []int         `json:"input_shape,omitempty"`
+	Hidden_Count            []int         `json:"number_of_hidden_nodes"`
+	Output_Count            int           `json:"number_of_output_nodes"`
+	Activations             []string      `json:"activations"`
+	Layers                  []Layer_Spec  `json:"layers"`
+	Weights                 [][][]float64 `json:"weights"`
+}
+
+//********************************************************************
+// Name:	New_Network
+// Description: This function creates a network with the layers the
+//		config describes and either draws the weights with the
+//		config's initializers or sets them to 0 depending on
+//		the bool random. The random weights are drawn using
+//		the config's seed. Batch norm layers always start with
+//		a scale of 1 and a shift of 0.
+// Return:	returns a pointer to the new network.
+//********************************************************************
+
+func New_Network(config *Config, random bool) *Network {
+	network := &Network{
+		Input_Count  : config.Input_Count,
+		Input_Shape  : config.Input_Shape,
+		Layers       : config.layer_specs(),
+	}
+	layers := network.layers()
+	network.describe(layers)
+	network.Weights = network.create_weights()
+	network.bind(layers, network.Weights, nil)
+	var generator *rand.Rand
+	if random {
+		generator = New_Random(config.Random_Seed).generator()
+	}
+	for _, layer := range layers {
+		layer.initialize(layer.parameters(), &config.Initialization, generator)
+	}
+	return network
+}
+
+//********************************************************************
+// Name:	build_layers
+// Description: This function builds the network's layers from their
+//		specs.
+// Return:	returns the layers, or an error if the specs can not
+//		be built.
+//********************************************************************
+
+func (network *Network) build_layers() ([]Layer, error) {
+	return build_layers(network.Layers, network.input_shape())
+}
+
+//********************************************************************
+// Name:	input_shape
+// Description: This function finds the shape of the network's input
+//		values, which is a flat list of them unless it was
+//		given one.
+// Return:	returns the shape.
+//********************************************************************
+
+func (network *Network) input_shape() []int {
+	if network.Input_Shape != nil {
+		return network.Input_Shape
+	}
+	return []int{network.Input_Count - 1}
+}
+
+//********************************************************************
+// Name:	layers
+// Description: This function builds the network's layers and binds
+//		them to its weights. A network made by New_Network or
+//		read by Load_Model has already been checked, so a
+//		failure here is a bug.
+// Return:	returns the layers.
+//********************************************************************
+
+func (network *Network) layers() []Layer {
+	layers, err := network.build_layers()
+	if err != nil {
+		panic(err)
+	}
+	if network.Weights != nil {
+		network.bind(layers, network.Weights, nil)
+	}
+	return layers
+}
+
+//********************************************************************
+// Name:	bind
+// Description: This function hands each layer its matrices of the
+//		parameters, and of the gradients if they are not nil.
+//********************************************************************
+
+func (network *Network) bind(layers []Layer, parameters [][][]float64, gradients [][][]float64) {
+	index := 0
+	for _, layer := range layers {
+		count := len(layer.shapes())
+		if gradients != nil {
+			layer.bind(parameters[index:index + count], gradients[index:index + count])
+		} else {
+			layer.bind(parameters[index:index + count], nil)
+		}
+		index += count
+	}
+}
+
+//********************************************************************
+// Name:	describe
+// Description: This function fills in the fields that sum up the
+//		network's dense layers.
+//********************************************************************
+
+func (network *Network) describe(layers []Layer) {
+	network.Hidden_Count = nil
+	network.Activations = nil
+	for i, spec := range network.Layers {
+		if spec.Type != Dense_Layer {
+			continue
+		}
+		activation := Linear
+		for _, next := range network.Layers[i + 1:] {
+			if next.Type == Dense_Layer {
+				break
+			}
+			if next.Type == Activation_Layer {
+				activation = next.Activation
+			}
+		}
+		network.Hidden_Count = append(network.Hidden_Count, spec.Nodes)
+		network.Activations = append(network.Activations, activation)
+	}
+	if len(network.Hidden_Count) > 0 {
+		network.Hidden_Count = network.Hidden_Count[:len(network.Hidden_Count) - 1]
+	}
+	network.Output_Count = shape_size(layers[len(layers) - 1].output_shape())
+}
+
+//********************************************************************
+// Name:	hidden_activations
+// Description: This function lists the activation function that
+//		follows each hidden dense layer.
+// Return:	returns a copy of every activation but the last.
+//********************************************************************
+
+func (network *Network) hidden_activations() []string {
+	if len(network.Activations) == 0 {
+		return nil
+	}
+	return append([]string(nil), network.Activations[:len(network.Activations) - 1]...)
+}
+
+//********************************************************************
+// Name:	output_activation
+// Description: This function finds the activation function of the
+//		network's outputs.
+// Return:	returns the last layer's activation function, or
+//		linear if the last layer is not an activation layer.
+//********************************************************************
+
+func (network *Network) output_activation() string {
+	last := network.Layers[len(network.Layers) - 1]
+	if last.Type == Activation_Layer {
+		return last.Activation
+	}
+	return Linear
+}
+
+//********************************************************************
+// Name:	shapes
+// Description: This function lists the shape of every matrix of
+//		parameters the layers have, in order.
+// Return:	returns the rows and columns of each matrix.
+//********************************************************************
+
+func shapes(layers []Layer) [][2]int {
+	var shapes [][2]int
+	for _, layer := range layers {
+		shapes = append(shapes, layer.shapes()...)
+	}
+	return shapes
+}
+
+//********************************************************************
+// Name:	regularized_layers
+// Description: This function marks which matrices of weights the
+//		penalties and max norms apply to.
+// Return:	returns an array with one bool for each matrix.
+//********************************************************************
+
+func (network *Network) regularized_layers() []bool {
+	var regularized []bool
+	for _, layer := range network.layers() {
+		for range layer.shapes() {
+			regularized = append(regularized, layer.regularized())
+		}
+	}
+	return regularized
+}
+
+//********************************************************************
+// Name:	check_weights
+// Description: This function checks that some weights, or a tensor
+//		kept for each weight, are shaped like the network.
+// Return:	returns an error naming the first matrix that is
+//		shaped wrong.
+//********************************************************************
+
+func (network *Network) check_weights(weights [][][]float64) error {
+	layers, err := network.build_layers()
+	if err != nil {
+		return err
+	}
+	shapes := shapes(layers)
+	if len(weights) != len(shapes) {
+		return fmt.Errorf("There are %d layers of weights, but the network needs %d.", len(weights), len(shapes))
+	}
+	for layer_index, shape := range shapes {
+		rows, columns := shape[0], shape[1]
+		if len(weights[layer_index]) != rows {
+			return fmt.Errorf("Layer %d of the weights has %d rows, but the network needs %d.",
+				layer_index, len(weights[layer_index]), rows)
+		}
+		for k := 0; k < rows; k++ {
+			if len(weights[layer_index][k]) != columns {
+				return fmt.Errorf("Row %d of layer %d of the weights has %d columns, but the network needs %d.",
+					k, layer_index, len(weights[layer_index][k]), columns)
+			}
+		}
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	create_weights
+// Description: This function creates a set of weights all set to 0,
+//		with a matrix shaped for each of the layers' matrices
+//		of parameters.
+// Return:	returns a 3D array of weights shaped like the network.
+//********************************************************************
+
+func (network *Network) create_weights() [][][]float64 {
+	layers, _ := network.build_layers()
+	var weights [][][]float64
+	for _, shape := range shapes(layers) {
+		new_layer := make([][]float64, shape[0])
+		for k := range new_layer {
+			new_layer[k] = make([]float64, shape[1])
+		}
+		weights = append(weights, new_layer)
+	}
+	return weights
+}
+
+//********************************************************************
+// Name:	copy_weights
+// Description: This function makes a deep copy of some weights.
+// Return:	returns a 3D array holding the copy.
+//********************************************************************
+
+func copy_weights(weights [][][]float64) [][][]float64 {
+	var copied [][][]float64
+	for layer_index := 0; layer_index < len(weights); layer_index++ {
+		var layer [][]float64
+		for node_index := 0; node_index < len(weights[layer_index]); node_index++ {
+			layer = append(layer, append([]float64(nil), weights[layer_index][node_index]...))
+		}
+		copied = append(copied, layer)
+	}
+	return copied
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function runs a batch of values through the
+//		layers. The values do not include the bias value.
+// Return:	returns the outputs of the last layer for each input.
+//********************************************************************
+
+func forward(layers []Layer, values [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	for _, layer := range layers {
+		values = layer.forward(values, training, generator)
+	}
+	return values
+}
+
+//********************************************************************
+// Name:	Predict_Batch
+// Description: This function runs many inputs' values through the
+//		network, batch_size of them at a time, building the
+//		layers only once. Each input's values must start with
+//		the bias value 1 the same way Read_CSV builds them.
+// Return:	returns an array with the output nodes of each input.
+//********************************************************************
+
+func (network *Network) Predict_Batch(values [][]float64, batch_size int) [][]float64 {
+	if batch_size < 1 {
+		batch_size = 1
+	}
+	layers := network.layers()
+	outputs := make([][]float64, 0, len(values))
+	for start := 0; start < len(values); start += batch_size {
+		end := start + batch_size
+		if end > len(values) {
+			end = len(values)
+		}
+		batch := make([][]float64, end - start)
+		for s := range batch {
+			batch[s] = values[start + s][1:]
+		}
+		outputs = append(outputs, forward(layers, batch, false, nil)...)
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	predict
+// Description: This function runs every input of a data set through
+//		the network in batches of the config's batch size.
+// Return:	returns an array with the output nodes of each input.
+//********************************************************************
+
+func (network *Network) predict(config *Config, data []Input) [][]float64 {
+	values := make([][]float64, len(data))
+	for data_index := range data {
+		values[data_index] = data[data_index].Values
+	}
+	return network.Predict_Batch(values, config.Batch_Size)
+}
+
+//********************************************************************
+// Name:	Predict
+// Description: This function runs the input values through the
+//		network. The values must start with the bias value 1
+//		the same way Read_CSV builds them.
+// Return:	returns an array of the output nodes. With a softmax
+//		output layer these are the probabilities of each
+//		input type.
+//********************************************************************
+
+func (network *Network) Predict(values []float64) []float64 {
+	return network.Predict_Batch([][]float64{values}, 1)[0]
+}
+
+//********************************************************************
+// Name:	Classify
+// Description: This function runs the input values through the
+//		network and picks the output node with the highest
+//		value.
+// Return:	returns the index of the winning output node.
+//********************************************************************
+
+func (network *Network) Classify(values []float64) int {
+	outputs := network.Predict(values)
+	// check for the highest dot product in the array
+	highest_product := 0
+	for input_index := 1; input_index < network.Output_Count; input_index++ {
+		if outputs[highest_product] < outputs[input_index] {
+			highest_product = input_index
+		}
+	}
+	return highest_product
+}
@@ -0,0 +1,209 @@
+package dnn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//********************************************************************
+// Name:	check_shape
+// Description: This function checks that every layer of the network's
+//		weights has one row for each node it feeds and one
+//		column for each node feeding it, plus the bias.
+//********************************************************************
+
+func check_shape(t *testing.T, network *Network, input_count int, hidden_count []int, output_count int) {
+	t.Helper()
+	sizes := append(append([]int{input_count - 1}, hidden_count...), output_count)
+	if len(network.Weights) != len(sizes) - 1 {
+		t.Fatalf("There are %d layers of weights, but there should be %d.", len(network.Weights), len(sizes) - 1)
+	}
+	for layer_index := range network.Weights {
+		if len(network.Weights[layer_index]) != sizes[layer_index + 1] {
+			t.Errorf("Layer %d has %d rows, but should have %d.",
+				layer_index, len(network.Weights[layer_index]), sizes[layer_index + 1])
+		}
+		for k := range network.Weights[layer_index] {
+			if len(network.Weights[layer_index][k]) != sizes[layer_index] + 1 {
+				t.Errorf("Row %d of layer %d has %d columns, but should have %d.",
+					k, layer_index, len(network.Weights[layer_index][k]), sizes[layer_index] + 1)
+			}
+		}
+	}
+}
+
+var heterogeneous_widths = [][]int{{6, 3}, {3, 7, 2}, {2, 9}, {8, 1, 5}}
+
+func TestWeightShapes(t *testing.T) {
+	for _, hidden_count := range heterogeneous_widths {
+		t.Run(fmt.Sprint(hidden_count), func(t *testing.T) {
+			config, network, data := gradient_check_network(hidden_count, Tanh, Sigmoid, Squared_Error_Loss)
+			check_shape(t, network, config.Input_Count, hidden_count, config.Output_Count)
+			if err := network.check_weights(network.Weights); err != nil {
+				t.Error(err)
+			}
+			for _, data_point := range data {
+				if outputs := network.Predict(data_point.Values); len(outputs) != config.Output_Count {
+					t.Errorf("Predict gave %d outputs, but there should be %d.", len(outputs), config.Output_Count)
+				}
+			}
+		})
+	}
+}
+
+func TestGradientCheckWidths(t *testing.T) {
+	for _, hidden_count := range heterogeneous_widths {
+		t.Run(fmt.Sprint(hidden_count), func(t *testing.T) {
+			config, network, data := gradient_check_network(hidden_count, Tanh, Softmax, Categorical_Cross_Entropy)
+			result := network.Gradient_Check(config, data, 1e-5)
+			if !result.Passed(Gradient_Check_Tolerance) {
+				t.Error(result)
+			}
+		})
+	}
+}
+
+func TestTrainWidths(t *testing.T) {
+	config, network, data := gradient_check_network([]int{7, 3}, Tanh, Sigmoid, Squared_Error_Loss)
+	config.Epoch_Count = 2
+	config.Dropout_Rates = []float64{.2, .2}
+	network.Train(config, data, data)
+	check_shape(t, network, config.Input_Count, config.Hidden_Count, config.Output_Count)
+}
+
+func TestBatchSizeOneIsPerRow(t *testing.T) {
+	config, network, data := gradient_check_network([]int{4}, Sigmoid, Sigmoid, Squared_Error_Loss)
+	config.Batch_Size = 1
+	config.Epoch_Count = 3
+	config.Learning_Rate = .5
+	config.Shuffle_Data = false
+	config.Progress_Tracker = false
+	config.Test_While_Training = false
+	saved, err := json.Marshal(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows Network
+	if err := json.Unmarshal(saved, &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	state := network.New_Training_State(config)
+	network.Resume(config, data, nil, state)
+
+	// Training on one input at a time in a batch that could hold all of
+	// them still updates the weights once for each input.
+	row_config := *config
+	row_config.Batch_Size = len(data)
+	row_config.Epoch_Count = 1
+	row_state := rows.New_Training_State(config)
+	for epoch := 0; epoch < config.Epoch_Count; epoch++ {
+		for i := range data {
+			row_state.Epoch = 0
+			rows.Resume(&row_config, data[i:i + 1], nil, row_state)
+		}
+	}
+
+	for layer_index := range rows.Weights {
+		for k := range rows.Weights[layer_index] {
+			for j := range rows.Weights[layer_index][k] {
+				if math.Abs(network.Weights[layer_index][k][j] - rows.Weights[layer_index][k][j]) > 1e-12 {
+					t.Fatalf("Weight %d of node %d in layer %d is %g after training with a batch size of 1, but updating after every input gives %g.",
+						j, k, layer_index, network.Weights[layer_index][k][j], rows.Weights[layer_index][k][j])
+				}
+			}
+		}
+	}
+}
+
+func TestLoadWidths(t *testing.T) {
+	directory, err := ioutil.TempDir("", "dnn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	config, network, _ := gradient_check_network([]int{3, 7, 2}, Tanh, Sigmoid, Squared_Error_Loss)
+	for _, format := range []string{JSON_File_Format, Binary_File_Format} {
+		t.Run(format, func(t *testing.T) {
+			file_name := filepath.Join(directory, "model." + format)
+			err := New_Model(network, config).Save(file_name, format, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			model, err := Load_Model(file_name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(model.Network.Weights) != fmt.Sprint(network.Weights) {
+				t.Error("The loaded weights do not match the saved weights.")
+			}
+		})
+	}
+
+	t.Run("mismatched", func(t *testing.T) {
+		// the hidden to hidden layer with its rows and columns swapped
+		model := New_Model(network, config)
+		model.Network = &Network{
+			Input_Count  : network.Input_Count,
+			Layers       : network.Layers,
+			Weights      : copy_weights(network.Weights),
+		}
+		model.Network.Weights[1] = make([][]float64, 3)
+		for k := range model.Network.Weights[1] {
+			model.Network.Weights[1][k] = make([]float64, 8)
+		}
+		file_name := filepath.Join(directory, "mismatched.json")
+		if err := model.Save(file_name, JSON_File_Format, 64); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load_Model(file_name); err == nil {
+			t.Error("A model with mis-shaped weights was loaded.")
+		}
+	})
+}
+
+func TestAdamWDecay(t *testing.T) {
+	for _, include_bias := range []bool{false, true} {
+		config := gradient_check_config([]int{3}, Tanh, Sigmoid, Squared_Error_Loss)
+		config.Batch_Normalization.Layers = []bool{true}
+		config.Optimizer.Type = AdamW_Optimizer
+		config.Optimizer.Weight_Decay = .1
+		config.Regularization.Include_Bias = include_bias
+		network, _ := gradient_check_setup(config)
+		before := copy_weights(network.Weights)
+
+		// With a gradient of 0 adam does not move the weights, so only the
+		// decay changes them.
+		layers := network.layers()
+		batch := network.new_gradient()
+		batch.count = 1
+		state := &Optimizer_State{First_Moment: network.create_weights(), Second_Moment: network.create_weights()}
+		network.apply_gradient(config, layers, batch, .5, state, make([]bool, len(network.Weights)))
+
+		layer_index := 0
+		for _, layer := range layers {
+			for range layer.parameters() {
+				for k := range before[layer_index] {
+					for j, weight := range before[layer_index][k] {
+						expected := weight
+						if layer.regularized() && (j > 0 || include_bias) {
+							expected = weight * (1 - .5 * .1)
+						}
+						if math.Abs(network.Weights[layer_index][k][j] - expected) > 1e-12 {
+							t.Fatalf("With include_bias %v, weight %d of node %d in layer %d went from %g to %g, but it should be %g.",
+								include_bias, j, k, layer_index, weight,
+								network.Weights[layer_index][k][j], expected)
+						}
+					}
+				}
+				layer_index++
+			}
+		}
+	}
+}
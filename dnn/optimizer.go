@@ -0,0 +1,217 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+)
+
+// The optimizers that can be used to update the weights.
+const (
+	SGD_Optimizer           = "sgd"
+	Nesterov_Optimizer      = "nesterov"
+	AdaGrad_Optimizer       = "adagrad"
+	RMSProp_Optimizer       = "rmsprop"
+	Adam_Optimizer          = "adam"
+	AdamW_Optimizer         = "adamw"
+)
+
+//********************************************************************
+// Name:	Optimizer
+// Description: The rule used to turn gradients into weight updates
+//		and its settings. SGD and Nesterov use the config's
+//		momentum, RMSProp averages the squared gradients with
+//		Decay_Rate, Adam and AdamW use Beta_1 and Beta_2 for
+//		their moment averages, and AdamW also shrinks the
+//		weights the regularization covers by Weight_Decay
+//		each step. Epsilon keeps the adaptive optimizers from
+//		dividing by zero.
+//********************************************************************
+
+type Optimizer struct {
+	Type                    string        `json:"type"`
+	Beta_1                  float64       `json:"beta_1"`
+	Beta_2                  float64       `json:"beta_2"`
+	Decay_Rate              float64       `json:"decay_rate"`
+	Epsilon                 float64       `json:"epsilon"`
+	Weight_Decay            float64       `json:"weight_decay"`
+}
+
+//********************************************************************
+// Name:	Optimizer_State
+// Description: The tensors an optimizer keeps between updates, each
+//		shaped like the network's weights. First_Moment is the
+//		momentum or running average of the gradients, and
+//		Second_Moment is the sum or running average of the
+//		squared gradients. Step counts the updates so far.
+//********************************************************************
+
+type Optimizer_State struct {
+	Step                    int           `json:"step"`
+	First_Moment            [][][]float64 `json:"first_moment"`
+	Second_Moment           [][][]float64 `json:"second_moment"`
+}
+
+//********************************************************************
+// Name:	optimizer
+// Description: An update rule. update takes one weight, its gradient
+//		and its slots in the state tensors, and returns the
+//		weight's new value.
+//********************************************************************
+
+type optimizer interface {
+	update(weight float64, gradient float64, learning_rate float64, first *float64, second *float64) float64
+}
+
+type sgd_optimizer struct {
+	momentum                float64
+}
+
+type nesterov_optimizer struct {
+	momentum                float64
+}
+
+type adagrad_optimizer struct {
+	epsilon                 float64
+}
+
+type rmsprop_optimizer struct {
+	decay_rate              float64
+	epsilon                 float64
+}
+
+type adam_optimizer struct {
+	beta_1                  float64
+	beta_2                  float64
+	epsilon                 float64
+	first_correction        float64
+	second_correction       float64
+}
+
+//********************************************************************
+// Name:	error_check
+// Description: This function checks the optimizer's settings.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (settings *Optimizer) error_check() error {
+	switch settings.Type {
+	case SGD_Optimizer, Nesterov_Optimizer, AdaGrad_Optimizer, RMSProp_Optimizer, Adam_Optimizer, AdamW_Optimizer:
+	default:
+		return fmt.Errorf("%s is not a known optimizer.", settings.Type)
+	}
+	if settings.Beta_1 < 0 || settings.Beta_1 >= 1 || settings.Beta_2 < 0 || settings.Beta_2 >= 1 {
+		return fmt.Errorf("The optimizer's betas must be at least 0 and less than 1.")
+	}
+	if settings.Decay_Rate < 0 || settings.Decay_Rate >= 1 {
+		return fmt.Errorf("The optimizer's decay rate must be at least 0 and less than 1.")
+	}
+	if settings.Epsilon <= 0 || settings.Weight_Decay < 0 {
+		return fmt.Errorf("The optimizer's epsilon must be greater than 0, and its weight decay can not be negative.")
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	new_optimizer
+// Description: This function builds the update rule the config asks
+//		for, ready for the given update step.
+// Return:	returns the update rule.
+//********************************************************************
+
+func (config *Config) new_optimizer(step int) optimizer {
+	settings := config.Optimizer
+	switch settings.Type {
+	case Nesterov_Optimizer:
+		return nesterov_optimizer{momentum: config.Momentum}
+	case AdaGrad_Optimizer:
+		return adagrad_optimizer{epsilon: settings.Epsilon}
+	case RMSProp_Optimizer:
+		return rmsprop_optimizer{decay_rate: settings.Decay_Rate, epsilon: settings.Epsilon}
+	case Adam_Optimizer, AdamW_Optimizer:
+		return adam_optimizer{
+			beta_1            : settings.Beta_1,
+			beta_2            : settings.Beta_2,
+			epsilon           : settings.Epsilon,
+			first_correction  : 1 - math.Pow(settings.Beta_1, float64(step)),
+			second_correction : 1 - math.Pow(settings.Beta_2, float64(step)),
+		}
+	}
+	return sgd_optimizer{momentum: config.Momentum}
+}
+
+//********************************************************************
+// Name:	decay
+// Description: This function finds how much of each weight the
+//		optimizer takes away every step, apart from its
+//		gradient step.
+// Return:	returns the weight decay for AdamW, and 0 for every
+//		other optimizer.
+//********************************************************************
+
+func (settings *Optimizer) decay() float64 {
+	if settings.Type == AdamW_Optimizer {
+		return settings.Weight_Decay
+	}
+	return 0
+}
+
+//********************************************************************
+// Name:	update
+// Description: Classical momentum, the first moment holds the last
+//		change made to the weight.
+//********************************************************************
+
+func (rule sgd_optimizer) update(weight float64, gradient float64, learning_rate float64, first *float64, second *float64) float64 {
+	difference := -learning_rate * gradient + rule.momentum * *first
+	*first = difference
+	return weight + difference
+}
+
+//********************************************************************
+// Name:	update
+// Description: Nesterov momentum, which looks ahead along the
+//		velocity in the first moment before stepping.
+//********************************************************************
+
+func (rule nesterov_optimizer) update(weight float64, gradient float64, learning_rate float64, first *float64, second *float64) float64 {
+	previous := *first
+	*first = rule.momentum * previous - learning_rate * gradient
+	return weight - rule.momentum * previous + (1 + rule.momentum) * *first
+}
+
+//********************************************************************
+// Name:	update
+// Description: AdaGrad, which scales each weight's step down by the
+//		sum of its squared gradients in the second moment.
+//********************************************************************
+
+func (rule adagrad_optimizer) update(weight float64, gradient float64, learning_rate float64, first *float64, second *float64) float64 {
+	*second += gradient * gradient
+	return weight - learning_rate * gradient / (math.Sqrt(*second) + rule.epsilon)
+}
+
+//********************************************************************
+// Name:	update
+// Description: RMSProp, which scales each weight's step down by a
+//		running average of its squared gradients.
+//********************************************************************
+
+func (rule rmsprop_optimizer) update(weight float64, gradient float64, learning_rate float64, first *float64, second *float64) float64 {
+	*second = rule.decay_rate * *second + (1 - rule.decay_rate) * gradient * gradient
+	return weight - learning_rate * gradient / (math.Sqrt(*second) + rule.epsilon)
+}
+
+//********************************************************************
+// Name:	update
+// Description: Adam, using bias corrected running averages of the
+//		gradient and squared gradient. AdamW steps the same
+//		way, and apply_gradient shrinks the weight separately.
+//********************************************************************
+
+func (rule adam_optimizer) update(weight float64, gradient float64, learning_rate float64, first *float64, second *float64) float64 {
+	*first = rule.beta_1 * *first + (1 - rule.beta_1) * gradient
+	*second = rule.beta_2 * *second + (1 - rule.beta_2) * gradient * gradient
+	first_estimate := *first / rule.first_correction
+	second_estimate := *second / rule.second_correction
+	return weight - learning_rate * first_estimate / (math.Sqrt(second_estimate) + rule.epsilon)
+}
@@ -0,0 +1,153 @@
+package dnn
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	pool_layer
+// Description: A layer that shrinks each channel of its inputs by
+//		taking the max or the average of each window of them.
+//		Max pooling remembers which input won each window so
+//		only that input is trained.
+//********************************************************************
+
+type pool_layer struct {
+	no_parameters
+	max                     bool
+	slide                   window
+	input_size              int
+	winners                 [][]int
+}
+
+//********************************************************************
+// Name:	new_pool_layer
+// Description: This function builds a max_pool2d or avg_pool2d layer
+//		from its spec.
+// Return:	returns the layer, or an error if the spec can not be
+//		built.
+//********************************************************************
+
+func new_pool_layer(spec *Layer_Spec, input_shape []int) (Layer, error) {
+	if spec.Padding != nil {
+		return nil, fmt.Errorf("A %s layer does not take any padding.", spec.Type)
+	}
+	slide, err := new_window(spec.Type, input_shape, 2, "pool size", spec.Pool_Size, spec.Stride, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &pool_layer{max: spec.Type == Max_Pool2D_Layer, slide: slide, input_size: shape_size(input_shape)}, nil
+}
+
+func (layer *pool_layer) output_shape() []int {
+	return []int{layer.slide.out_height, layer.slide.out_width, layer.slide.channels}
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function pools every window of every input.
+// Return:	returns the pooled values.
+//********************************************************************
+
+func (layer *pool_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	slide := &layer.slide
+	area := float64(slide.kernel_height * slide.kernel_width)
+	outputs := make([][]float64, len(inputs))
+	layer.winners = make([][]int, len(inputs))
+	for s, values := range inputs {
+		outputs[s] = make([]float64, slide.out_height * slide.out_width * slide.channels)
+		if layer.max {
+			layer.winners[s] = make([]int, len(outputs[s]))
+		}
+		for out_y := 0; out_y < slide.out_height; out_y++ {
+			for out_x := 0; out_x < slide.out_width; out_x++ {
+				out := (out_y * slide.out_width + out_x) * slide.channels
+				for c := 0; c < slide.channels; c++ {
+					winner := -1
+					total := 0.0
+					for dy := 0; dy < slide.kernel_height; dy++ {
+						for dx := 0; dx < slide.kernel_width; dx++ {
+							index := slide.input_index(out_y, out_x, dy, dx) + c
+							total += values[index]
+							if winner < 0 || values[index] > values[winner] {
+								winner = index
+							}
+						}
+					}
+					if layer.max {
+						outputs[s][out + c] = values[winner]
+						layer.winners[s][out + c] = winner
+					} else {
+						outputs[s][out + c] = total / area
+					}
+				}
+			}
+		}
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function passes each pooled gradient back to the
+//		input that won its window, or shares it evenly over
+//		the window when averaging.
+// Return:	returns the gradient with respect to the inputs.
+//********************************************************************
+
+func (layer *pool_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	if !inputs {
+		return nil
+	}
+	slide := &layer.slide
+	area := float64(slide.kernel_height * slide.kernel_width)
+	input_gradients := make([][]float64, len(output_gradients))
+	for s, gradient := range output_gradients {
+		input_gradients[s] = make([]float64, layer.input_size)
+		for out_y := 0; out_y < slide.out_height; out_y++ {
+			for out_x := 0; out_x < slide.out_width; out_x++ {
+				out := (out_y * slide.out_width + out_x) * slide.channels
+				for c := 0; c < slide.channels; c++ {
+					if layer.max {
+						input_gradients[s][layer.winners[s][out + c]] += gradient[out + c]
+						continue
+					}
+					for dy := 0; dy < slide.kernel_height; dy++ {
+						for dx := 0; dx < slide.kernel_width; dx++ {
+							input_gradients[s][slide.input_index(out_y, out_x, dy, dx) + c] += gradient[out + c] / area
+						}
+					}
+				}
+			}
+		}
+	}
+	return input_gradients
+}
+
+//********************************************************************
+// Name:	flatten_layer
+// Description: A layer that turns shaped values into a flat list of
+//		them. The values are already stored flat, so it only
+//		changes the shape the next layer sees.
+//********************************************************************
+
+type flatten_layer struct {
+	no_parameters
+	size                    int
+}
+
+func (layer *flatten_layer) output_shape() []int {
+	return []int{layer.size}
+}
+
+func (layer *flatten_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	return inputs
+}
+
+func (layer *flatten_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	if !inputs {
+		return nil
+	}
+	return output_gradients
+}
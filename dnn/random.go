@@ -0,0 +1,79 @@
+package dnn
+
+import (
+	"math/rand"
+	"time"
+)
+
+//********************************************************************
+// Name:	Random
+// Description: A splitmix64 random number source. Unlike the sources
+//		in math/rand its whole state is the exported State
+//		field, so it can be saved in a checkpoint and picked
+//		back up exactly where it left off.
+//********************************************************************
+
+type Random struct {
+	State                   uint64        `json:"state"`
+}
+
+//********************************************************************
+// Name:	New_Random
+// Description: This function creates a random source from the seed,
+//		or from the current time if the seed is 0.
+// Return:	returns a pointer to the new random source.
+//********************************************************************
+
+func New_Random(seed int64) *Random {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Random{State: uint64(seed)}
+}
+
+//********************************************************************
+// Name:	Seed
+// Description: This function resets the random source to the seed.
+//********************************************************************
+
+func (random *Random) Seed(seed int64) {
+	random.State = uint64(seed)
+}
+
+//********************************************************************
+// Name:	Uint64
+// Description: This function moves the random source forward one
+//		step.
+// Return:	returns the next random 64 bit number.
+//********************************************************************
+
+func (random *Random) Uint64() uint64 {
+	random.State += 0x9e3779b97f4a7c15
+	result := random.State
+	result = (result ^ (result >> 30)) * 0xbf58476d1ce4e5b9
+	result = (result ^ (result >> 27)) * 0x94d049bb133111eb
+	return result ^ (result >> 31)
+}
+
+//********************************************************************
+// Name:	Int63
+// Description: This function moves the random source forward one
+//		step.
+// Return:	returns the next random non-negative 63 bit number.
+//********************************************************************
+
+func (random *Random) Int63() int64 {
+	return int64(random.Uint64() >> 1)
+}
+
+//********************************************************************
+// Name:	generator
+// Description: This function wraps the source so the helpers in
+//		math/rand can be used with it. The wrapper keeps no
+//		state of its own, so saving the source is enough.
+// Return:	returns a math/rand generator drawing from the source.
+//********************************************************************
+
+func (random *Random) generator() *rand.Rand {
+	return rand.New(random)
+}
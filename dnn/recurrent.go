@@ -0,0 +1,292 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+//********************************************************************
+// Name:	recurrent_layer
+// Description: A layer that reads inputs shaped timesteps x features
+//		one timestep at a time, feeding the state it leaves
+//		after each one into the next. An rnn layer's state is
+//		its Activation of one set of dot products. An lstm
+//		layer has input, forget, cell and output gates and
+//		keeps a cell besides its state. A gru layer has
+//		update, reset and candidate gates, and its candidate
+//		gate sees the state after the reset gate scales it.
+//		Each gate has a row of weights for every node, holding
+//		the bias in column 0, then a weight for each feature,
+//		then a weight for each node of the last state, all in
+//		one matrix. The layer outputs its last state, or its
+//		state after every timestep if the spec asks for
+//		sequences.
+//********************************************************************
+
+type recurrent_layer struct {
+	weight_matrix
+	activation              string
+	timesteps               int
+	features                int
+	nodes                   int
+	gates                   int
+	inputs                  [][]float64
+	states                  [][][]float64
+	cells                   [][][]float64
+	gate_values             [][][]float64
+	dot_products            [][][]float64
+}
+
+//********************************************************************
+// Name:	new_recurrent_layer
+// Description: This function builds an rnn, lstm or gru layer from
+//		its spec.
+// Return:	returns the layer, or an error if the spec can not be
+//		built.
+//********************************************************************
+
+func new_recurrent_layer(spec *Layer_Spec, input_shape []int) (Layer, error) {
+	if len(input_shape) != 2 {
+		return nil, fmt.Errorf("A %s layer needs inputs shaped timesteps x features, but they are shaped %v.", spec.Type, input_shape)
+	}
+	if spec.Nodes <= 0 {
+		return nil, fmt.Errorf("A %s layer needs at least 1 node.", spec.Type)
+	}
+	matrix, err := new_weight_matrix(spec)
+	if err != nil {
+		return nil, err
+	}
+	layer := &recurrent_layer{weight_matrix: matrix, timesteps: input_shape[0], features: input_shape[1], nodes: spec.Nodes}
+	switch spec.Type {
+	case RNN_Layer:
+		layer.gates = 1
+		layer.activation = spec.Activation
+		if layer.activation == "" {
+			layer.activation = Tanh
+		}
+		if !Is_Activation(layer.activation) || layer.activation == Softmax {
+			return nil, fmt.Errorf("%s is not an activation function an rnn layer can use.", layer.activation)
+		}
+	case LSTM_Layer:
+		layer.gates = 4
+	case GRU_Layer:
+		layer.gates = 3
+	}
+	if spec.Type != RNN_Layer && spec.Activation != "" {
+		return nil, fmt.Errorf("Only rnn layers take an activation.")
+	}
+	return layer, nil
+}
+
+func (layer *recurrent_layer) output_shape() []int {
+	if layer.spec.Return_Sequences {
+		return []int{layer.timesteps, layer.nodes}
+	}
+	return []int{layer.nodes}
+}
+
+func (layer *recurrent_layer) shapes() [][2]int {
+	return [][2]int{{layer.gates * layer.nodes, 1 + layer.features + layer.nodes}}
+}
+
+//********************************************************************
+// Name:	dot_product
+// Description: This function runs one row of weights over a
+//		timestep's features and a state.
+// Return:	returns the dot product.
+//********************************************************************
+
+func (layer *recurrent_layer) dot_product(row []float64, features []float64, state []float64) float64 {
+	dot_product := row[0]
+	for i, feature := range features {
+		dot_product += row[1 + i] * feature
+	}
+	for j, value := range state {
+		dot_product += row[1 + layer.features + j] * value
+	}
+	return dot_product
+}
+
+//********************************************************************
+// Name:	forward
+// Description: This function runs every input through the layer one
+//		timestep at a time, starting from a state of zeros.
+// Return:	returns the last state of each input, or every state
+//		if the spec asks for sequences.
+//********************************************************************
+
+func (layer *recurrent_layer) forward(inputs [][]float64, training bool, generator *rand.Rand) [][]float64 {
+	n := layer.nodes
+	layer.inputs = inputs
+	layer.states = make([][][]float64, len(inputs))
+	layer.cells = make([][][]float64, len(inputs))
+	layer.gate_values = make([][][]float64, len(inputs))
+	layer.dot_products = make([][][]float64, len(inputs))
+	outputs := make([][]float64, len(inputs))
+	for s, values := range inputs {
+		states := [][]float64{make([]float64, n)}
+		cells := [][]float64{make([]float64, n)}
+		for t := 0; t < layer.timesteps; t++ {
+			features := values[t * layer.features:(t + 1) * layer.features]
+			last := states[t]
+			dot_products := make([]float64, layer.gates * n)
+			gates := make([]float64, layer.gates * n)
+			state := make([]float64, n)
+			switch layer.spec.Type {
+			case RNN_Layer:
+				for j := 0; j < n; j++ {
+					dot_products[j] = layer.dot_product(layer.weights[j], features, last)
+					state[j] = activate(layer.activation, dot_products[j])
+				}
+			case LSTM_Layer:
+				cell := make([]float64, n)
+				for k := range gates {
+					dot_products[k] = layer.dot_product(layer.weights[k], features, last)
+					if k / n == 2 {
+						gates[k] = math.Tanh(dot_products[k])
+					} else {
+						gates[k] = activate(Sigmoid, dot_products[k])
+					}
+				}
+				for j := 0; j < n; j++ {
+					cell[j] = gates[n + j] * cells[t][j] + gates[j] * gates[2 * n + j]
+					state[j] = gates[3 * n + j] * math.Tanh(cell[j])
+				}
+				cells = append(cells, cell)
+			case GRU_Layer:
+				for k := 0; k < 2 * n; k++ {
+					dot_products[k] = layer.dot_product(layer.weights[k], features, last)
+					gates[k] = activate(Sigmoid, dot_products[k])
+				}
+				reset := make([]float64, n)
+				for j := range reset {
+					reset[j] = gates[n + j] * last[j]
+				}
+				for j := 0; j < n; j++ {
+					dot_products[2 * n + j] = layer.dot_product(layer.weights[2 * n + j], features, reset)
+					gates[2 * n + j] = math.Tanh(dot_products[2 * n + j])
+					state[j] = (1 - gates[j]) * gates[2 * n + j] + gates[j] * last[j]
+				}
+			}
+			states = append(states, state)
+			layer.gate_values[s] = append(layer.gate_values[s], gates)
+			layer.dot_products[s] = append(layer.dot_products[s], dot_products)
+		}
+		layer.states[s] = states
+		layer.cells[s] = cells
+		if layer.spec.Return_Sequences {
+			for _, state := range states[1:] {
+				outputs[s] = append(outputs[s], state...)
+			}
+		} else {
+			outputs[s] = append([]float64(nil), states[layer.timesteps]...)
+		}
+	}
+	return outputs
+}
+
+//********************************************************************
+// Name:	backward
+// Description: This function backpropagates through time, walking
+//		back from the last timestep and carrying the gradient
+//		of each state, and of each cell for lstm layers, into
+//		the timestep before it. Every timestep shares the same
+//		weights, so their gradients are all added together.
+// Return:	returns the gradient with respect to the inputs, or
+//		nil if they are not needed.
+//********************************************************************
+
+func (layer *recurrent_layer) backward(output_gradients [][]float64, inputs bool) [][]float64 {
+	n := layer.nodes
+	var input_gradients [][]float64
+	if inputs {
+		input_gradients = make([][]float64, len(output_gradients))
+	}
+	for s, gradient := range output_gradients {
+		values := layer.inputs[s]
+		if inputs {
+			input_gradients[s] = make([]float64, len(values))
+		}
+		state_gradient := make([]float64, n)
+		cell_gradient := make([]float64, n)
+		for t := layer.timesteps - 1; t >= 0; t-- {
+			if layer.spec.Return_Sequences {
+				for j := range state_gradient {
+					state_gradient[j] += gradient[t * n + j]
+				}
+			} else if t == layer.timesteps - 1 {
+				copy(state_gradient, gradient)
+			}
+			features := values[t * layer.features:(t + 1) * layer.features]
+			last := layer.states[s][t]
+			state := layer.states[s][t + 1]
+			gates := layer.gate_values[s][t]
+			dot_products := layer.dot_products[s][t]
+			deltas := make([]float64, layer.gates * n)
+			last_gradient := make([]float64, n)
+			recurrent := make([][]float64, layer.gates * n)
+			for k := range recurrent {
+				recurrent[k] = last
+			}
+
+			switch layer.spec.Type {
+			case RNN_Layer:
+				for j := 0; j < n; j++ {
+					deltas[j] = state_gradient[j] * derivative(layer.activation, dot_products[j], state[j])
+				}
+			case LSTM_Layer:
+				for j := 0; j < n; j++ {
+					cell := layer.cells[s][t + 1][j]
+					squashed := math.Tanh(cell)
+					cell_gradient[j] += state_gradient[j] * gates[3 * n + j] * (1 - squashed * squashed)
+					deltas[j] = cell_gradient[j] * gates[2 * n + j] * gates[j] * (1 - gates[j])
+					deltas[n + j] = cell_gradient[j] * layer.cells[s][t][j] * gates[n + j] * (1 - gates[n + j])
+					deltas[2 * n + j] = cell_gradient[j] * gates[j] * (1 - gates[2 * n + j] * gates[2 * n + j])
+					deltas[3 * n + j] = state_gradient[j] * squashed * gates[3 * n + j] * (1 - gates[3 * n + j])
+					cell_gradient[j] *= gates[n + j]
+				}
+			case GRU_Layer:
+				reset := make([]float64, n)
+				for j := 0; j < n; j++ {
+					reset[j] = gates[n + j] * last[j]
+					candidate := gates[2 * n + j]
+					deltas[2 * n + j] = state_gradient[j] * (1 - gates[j]) * (1 - candidate * candidate)
+					deltas[j] = state_gradient[j] * (last[j] - candidate) * gates[j] * (1 - gates[j])
+					last_gradient[j] = state_gradient[j] * gates[j]
+					recurrent[2 * n + j] = reset
+				}
+				for j := 0; j < n; j++ {
+					reset_gradient := 0.0
+					for k := 2 * n; k < 3 * n; k++ {
+						reset_gradient += deltas[k] * layer.weights[k][1 + layer.features + j]
+					}
+					deltas[n + j] = reset_gradient * last[j] * gates[n + j] * (1 - gates[n + j])
+					last_gradient[j] += reset_gradient * gates[n + j]
+				}
+			}
+
+			for k, delta := range deltas {
+				row := layer.weight_gradients[k]
+				row[0] += delta
+				for i, feature := range features {
+					row[1 + i] += delta * feature
+					if inputs {
+						input_gradients[s][t * layer.features + i] += layer.weights[k][1 + i] * delta
+					}
+				}
+				for j, value := range recurrent[k] {
+					row[1 + layer.features + j] += delta * value
+				}
+				if layer.spec.Type == GRU_Layer && k >= 2 * n {
+					continue
+				}
+				for j := range last_gradient {
+					last_gradient[j] += layer.weights[k][1 + layer.features + j] * delta
+				}
+			}
+			state_gradient = last_gradient
+		}
+	}
+	return input_gradients
+}
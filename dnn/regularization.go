@@ -0,0 +1,142 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+)
+
+//********************************************************************
+// Name:	Regularization
+// Description: Penalties that keep the weights small. L1 adds L1
+//		times the sum of the absolute weights to the loss, and
+//		L2 adds L2 / 2 times the sum of the squared weights.
+//		Max_Norm holds a limit for each layer of weights, and
+//		after every update the weights feeding each node are
+//		scaled down until their length is at most that limit.
+//		A limit of 0 leaves the layer alone. Bias weights are
+//		left out of all of them unless Include_Bias is set.
+//********************************************************************
+
+type Regularization struct {
+	L1                      float64       `json:"l1"`
+	L2                      float64       `json:"l2"`
+	Max_Norm                []float64     `json:"max_norm"`
+	Include_Bias            bool          `json:"include_bias"`
+}
+
+//********************************************************************
+// Name:	error_check
+// Description: This function checks the regularization settings
+//		against the number of matrices of weights the layers
+//		have. A count of 0 skips the max norm count check.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (regularization *Regularization) error_check(matrix_count int) error {
+	if regularization.L1 < 0 || regularization.L2 < 0 {
+		return fmt.Errorf("The L1 and L2 penalties can not be negative.")
+	}
+	if regularization.Max_Norm == nil {
+		return nil
+	}
+	if matrix_count > 0 && len(regularization.Max_Norm) != matrix_count {
+		return fmt.Errorf("There must be one max norm for each matrix of weights, which is %d.", matrix_count)
+	}
+	for _, limit := range regularization.Max_Norm {
+		if limit < 0 {
+			return fmt.Errorf("A max norm can not be negative.")
+		}
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	regularized
+// Description: This function checks if a weight is one the penalties
+//		apply to. Weight 0 of every node is its bias weight.
+// Return:	returns true if the weight is regularized.
+//********************************************************************
+
+func (regularization *Regularization) regularized(weight_index int) bool {
+	return weight_index > 0 || regularization.Include_Bias
+}
+
+//********************************************************************
+// Name:	penalty
+// Description: This function works out how much the L1 and L2
+//		penalties add to the loss for the layers of weights
+//		that are regularized.
+// Return:	returns the penalty.
+//********************************************************************
+
+func (regularization *Regularization) penalty(weights [][][]float64, regularized []bool) float64 {
+	if regularization.L1 == 0 && regularization.L2 == 0 {
+		return 0
+	}
+	penalty := 0.0
+	for layer_index := 0; layer_index < len(weights); layer_index++ {
+		if !regularized[layer_index] {
+			continue
+		}
+		for k := 0; k < len(weights[layer_index]); k++ {
+			for j := 0; j < len(weights[layer_index][k]); j++ {
+				if regularization.regularized(j) {
+					weight := weights[layer_index][k][j]
+					penalty += regularization.L1 * math.Abs(weight) + regularization.L2 * weight * weight / 2
+				}
+			}
+		}
+	}
+	return penalty
+}
+
+//********************************************************************
+// Name:	gradient
+// Description: This function works out the penalties' share of the
+//		gradient for one weight.
+// Return:	returns the gradient to add to the weight's gradient.
+//********************************************************************
+
+func (regularization *Regularization) gradient(weight_index int, weight float64) float64 {
+	if !regularization.regularized(weight_index) {
+		return 0
+	}
+	sign := 0.0
+	if weight > 0 {
+		sign = 1
+	} else if weight < 0 {
+		sign = -1
+	}
+	return regularization.L1 * sign + regularization.L2 * weight
+}
+
+//********************************************************************
+// Name:	constrain
+// Description: This function scales down the weights feeding each
+//		node of a layer whose length is past the layer's max
+//		norm.
+//********************************************************************
+
+func (regularization *Regularization) constrain(weights [][][]float64, layer_index int) {
+	if layer_index >= len(regularization.Max_Norm) || regularization.Max_Norm[layer_index] == 0 {
+		return
+	}
+	limit := regularization.Max_Norm[layer_index]
+	for k := 0; k < len(weights[layer_index]); k++ {
+		length := 0.0
+		for j := 0; j < len(weights[layer_index][k]); j++ {
+			if regularization.regularized(j) {
+				length += weights[layer_index][k][j] * weights[layer_index][k][j]
+			}
+		}
+		length = math.Sqrt(length)
+		if length <= limit {
+			continue
+		}
+		for j := 0; j < len(weights[layer_index][k]); j++ {
+			if regularization.regularized(j) {
+				weights[layer_index][k][j] *= limit / length
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package dnn
+
+import (
+	"testing"
+)
+
+func TestRegularizationErrorCheck(t *testing.T) {
+	dense := gradient_check_config([]int{5, 4}, Sigmoid, Sigmoid, Squared_Error_Loss)
+	conv, _, _ := gradient_check_layers([]int{4, 4, 2}, []Layer_Spec{
+		{Type: Conv2D_Layer, Filters: 3, Kernel_Size: []int{2}},
+		{Type: Activation_Layer, Activation: Tanh},
+		{Type: Max_Pool2D_Layer, Pool_Size: []int{2}},
+		{Type: Flatten_Layer},
+		{Type: Dense_Layer, Nodes: 3},
+	}, Linear, Squared_Error_Loss)
+	tests := []struct {
+		name                    string
+		config                  *Config
+		max_norm                []float64
+		valid                   bool
+	}{
+		{"dense", dense, []float64{1, 0, 2}, true},
+		{"dense with a norm for each hidden layer", dense, []float64{1, 0}, false},
+		{"dense with an extra norm", dense, []float64{1, 0, 2, 3}, false},
+		{"conv", conv, []float64{1, 2}, true},
+		{"conv with a norm for each layer", conv, []float64{1, 0, 0, 0, 2}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			regularization := Regularization{Max_Norm: test.max_norm}
+			err := regularization.error_check(test.config.parameter_count())
+			if test.valid && err != nil {
+				t.Error(err)
+			}
+			if !test.valid && err == nil {
+				t.Error("The max norms were allowed.")
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+)
+
+// The kinds of learning rate schedule. An empty type keeps the
+// learning rate constant.
+const (
+	Step_Schedule           = "step"
+	Exponential_Schedule    = "exponential"
+	Cosine_Schedule         = "cosine"
+	Plateau_Schedule        = "plateau"
+)
+
+//********************************************************************
+// Name:	Schedule
+// Description: How the learning rate changes as training goes on.
+//		Steps are counted in epochs, or in batches when
+//		Per_Batch is set. Step decay multiplies the rate by
+//		Decay_Rate every Step_Size steps, exponential decay
+//		multiplies it by Decay_Rate every step, cosine anneals
+//		it down to Minimum_Rate over the whole run, and
+//		plateau multiplies it by Decay_Rate whenever the
+//		validation loss goes Patience epochs without
+//		improving. Any of them can start with Warmup_Steps
+//		steps where the rate climbs linearly up to where it
+//		should be.
+//********************************************************************
+
+type Schedule struct {
+	Type                    string        `json:"type"`
+	Per_Batch               bool          `json:"per_batch"`
+	Step_Size               int           `json:"step_size"`
+	Decay_Rate              float64       `json:"decay_rate"`
+	Minimum_Rate            float64       `json:"minimum_learning_rate"`
+	Warmup_Steps            int           `json:"warmup_steps"`
+	Patience                int           `json:"patience"`
+}
+
+//********************************************************************
+// Name:	Schedule_State
+// Description: What the plateau schedule has to remember between
+//		epochs. Rate is the reduced learning rate, Best is the
+//		lowest validation loss seen, and Waiting counts the
+//		epochs since it last improved.
+//********************************************************************
+
+type Schedule_State struct {
+	Rate                    float64       `json:"rate"`
+	Best                    float64       `json:"best"`
+	Waiting                 int           `json:"waiting"`
+}
+
+//********************************************************************
+// Name:	error_check
+// Description: This function checks the schedule's settings.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (schedule *Schedule) error_check() error {
+	switch schedule.Type {
+	case "", Cosine_Schedule:
+	case Step_Schedule:
+		if schedule.Step_Size <= 0 {
+			return fmt.Errorf("The step learning rate schedule needs a step size greater than 0.")
+		}
+		fallthrough
+	case Exponential_Schedule, Plateau_Schedule:
+		if schedule.Decay_Rate <= 0 || schedule.Decay_Rate > 1 {
+			return fmt.Errorf("The %s learning rate schedule needs a decay rate greater than 0 and at most 1.", schedule.Type)
+		}
+	default:
+		return fmt.Errorf("%s is not a known learning rate schedule.", schedule.Type)
+	}
+	if schedule.Type == Plateau_Schedule && schedule.Per_Batch {
+		return fmt.Errorf("The plateau learning rate schedule can only be applied per epoch.")
+	}
+	if schedule.Type == Plateau_Schedule && schedule.Patience < 1 {
+		return fmt.Errorf("The plateau learning rate schedule needs a patience of at least 1.")
+	}
+	if schedule.Warmup_Steps < 0 || schedule.Patience < 0 || schedule.Minimum_Rate < 0 {
+		return fmt.Errorf("The learning rate schedule's warmup steps, patience and minimum rate can not be negative.")
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	learning_rate
+// Description: This function works out the learning rate for a step,
+//		out of the total number of steps in the run.
+// Return:	returns the learning rate to train with.
+//********************************************************************
+
+func (config *Config) learning_rate(state *Schedule_State, step int, total_steps int) float64 {
+	schedule := config.Learning_Rate_Schedule
+	rate := config.Learning_Rate
+	switch schedule.Type {
+	case Step_Schedule:
+		rate *= math.Pow(schedule.Decay_Rate, float64(step / schedule.Step_Size))
+	case Exponential_Schedule:
+		rate *= math.Pow(schedule.Decay_Rate, float64(step))
+	case Cosine_Schedule:
+		if total_steps > 1 {
+			rate = schedule.Minimum_Rate + (rate - schedule.Minimum_Rate) *
+				(1 + math.Cos(math.Pi * float64(step) / float64(total_steps - 1))) / 2
+		}
+	case Plateau_Schedule:
+		if state.Rate != 0 {
+			rate = state.Rate
+		}
+	}
+	if rate < schedule.Minimum_Rate {
+		rate = schedule.Minimum_Rate
+	}
+	if step < schedule.Warmup_Steps {
+		rate *= float64(step + 1) / float64(schedule.Warmup_Steps)
+	}
+	return rate
+}
+
+//********************************************************************
+// Name:	plateau
+// Description: This function feeds the validation loss from the start
+//		of an epoch to the plateau schedule, cutting the rate
+//		when the loss has stopped improving.
+//********************************************************************
+
+func (config *Config) plateau(state *Schedule_State, validation_loss float64) {
+	schedule := config.Learning_Rate_Schedule
+	if schedule.Type != Plateau_Schedule {
+		return
+	}
+	if state.Rate == 0 {
+		state.Rate = config.Learning_Rate
+		state.Best = validation_loss
+		return
+	}
+	if validation_loss < state.Best {
+		state.Best = validation_loss
+		state.Waiting = 0
+		return
+	}
+	state.Waiting++
+	if state.Waiting >= schedule.Patience {
+		state.Rate = math.Max(state.Rate * schedule.Decay_Rate, schedule.Minimum_Rate)
+		state.Waiting = 0
+	}
+}
@@ -0,0 +1,82 @@
+package dnn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLearningRateSchedule(t *testing.T) {
+	tests := []struct {
+		name                    string
+		schedule                Schedule
+		steps                   []int
+		rates                   []float64
+	}{
+		{"constant", Schedule{}, []int{0, 5, 9}, []float64{.1, .1, .1}},
+		{"step", Schedule{Type: Step_Schedule, Step_Size: 3, Decay_Rate: .5}, []int{0, 2, 3, 7}, []float64{.1, .1, .05, .025}},
+		{"exponential", Schedule{Type: Exponential_Schedule, Decay_Rate: .9}, []int{0, 1, 3}, []float64{.1, .09, .0729}},
+		{"cosine", Schedule{Type: Cosine_Schedule, Minimum_Rate: .02}, []int{0, 3, 9}, []float64{.1, .08, .02}},
+		{"minimum rate", Schedule{Type: Exponential_Schedule, Decay_Rate: .5, Minimum_Rate: .03}, []int{1, 2, 5}, []float64{.05, .03, .03}},
+		{"warmup", Schedule{Warmup_Steps: 4}, []int{0, 1, 3, 4}, []float64{.025, .05, .1, .1}},
+		{"step with warmup", Schedule{Type: Step_Schedule, Step_Size: 2, Decay_Rate: .5, Warmup_Steps: 2}, []int{0, 1, 2}, []float64{.05, .1, .05}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := New_Config()
+			config.Learning_Rate = .1
+			config.Learning_Rate_Schedule = test.schedule
+			if err := test.schedule.error_check(); err != nil {
+				t.Fatal(err)
+			}
+			for i, step := range test.steps {
+				rate := config.learning_rate(&Schedule_State{}, step, 10)
+				if math.Abs(rate - test.rates[i]) > 1e-12 {
+					t.Errorf("The learning rate at step %d is %g, but should be %g.", step, rate, test.rates[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPlateauSchedule(t *testing.T) {
+	config := New_Config()
+	config.Learning_Rate = .1
+	config.Learning_Rate_Schedule = Schedule{Type: Plateau_Schedule, Decay_Rate: .5, Patience: 2, Minimum_Rate: .02}
+	losses := []float64{1, .8, .9, .85, .7, .75, .8, .9, .9, .9, .9}
+	rates := []float64{.1, .1, .1, .05, .05, .05, .025, .025, .02, .02, .02}
+	state := Schedule_State{}
+	for epoch, loss := range losses {
+		config.plateau(&state, loss)
+		rate := config.learning_rate(&state, epoch, len(losses))
+		if math.Abs(rate - rates[epoch]) > 1e-12 {
+			t.Errorf("The learning rate after a validation loss of %g at epoch %d is %g, but should be %g.",
+				loss, epoch, rate, rates[epoch])
+		}
+	}
+}
+
+func TestScheduleErrorCheck(t *testing.T) {
+	tests := []struct {
+		name                    string
+		schedule                Schedule
+		valid                   bool
+	}{
+		{"step", Schedule{Type: Step_Schedule, Step_Size: 2, Decay_Rate: .5}, true},
+		{"step without a step size", Schedule{Type: Step_Schedule, Decay_Rate: .5}, false},
+		{"plateau", Schedule{Type: Plateau_Schedule, Decay_Rate: .5, Patience: 1}, true},
+		{"plateau without a patience", Schedule{Type: Plateau_Schedule, Decay_Rate: .5}, false},
+		{"plateau per batch", Schedule{Type: Plateau_Schedule, Decay_Rate: .5, Patience: 1, Per_Batch: true}, false},
+		{"exponential without a decay rate", Schedule{Type: Exponential_Schedule}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.schedule.error_check()
+			if test.valid && err != nil {
+				t.Error(err)
+			}
+			if !test.valid && err == nil {
+				t.Error("The learning rate schedule was allowed.")
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package dnn
+
+import (
+	"fmt"
+	"math"
+)
+
+// The kinds of problem a network can be trained on. An empty task is
+// classification.
+const (
+	Classification_Task     = "classification"
+	Regression_Task         = "regression"
+	Multi_Label_Task        = "multi_label"
+)
+
+//********************************************************************
+// Name:	task
+// Description: This function names the task the config is for.
+// Return:	returns the task, which is classification if the
+//		config does not say.
+//********************************************************************
+
+func (config *Config) task() string {
+	if config.Task == "" {
+		return Classification_Task
+	}
+	return config.Task
+}
+
+//********************************************************************
+// Name:	task_error_check
+// Description: This function checks the config's task and the target
+//		scaling that goes with regression.
+// Return:	returns an error describing the first problem found.
+//********************************************************************
+
+func (config *Config) task_error_check() error {
+	switch config.Task {
+	case "", Classification_Task, Regression_Task, Multi_Label_Task:
+	default:
+		return fmt.Errorf("%s is not a known task, use %s, %s or %s.", config.Task,
+			Classification_Task, Regression_Task, Multi_Label_Task)
+	}
+	if config.Task == Multi_Label_Task && config.output_activation() == Softmax {
+		return fmt.Errorf("Softmax outputs always add up to 1, so they can not be used for %s.", Multi_Label_Task)
+	}
+	if config.Label_Threshold <= 0 || config.Label_Threshold >= 1 {
+		return fmt.Errorf("The label threshold must be greater than 0 and less than 1.")
+	}
+	if config.Target_Min == nil && config.Target_Max == nil {
+		// Only a new network's shape comes from the config, testing and fine
+		// tuning use the output activation of the saved model.
+		if config.Task == Regression_Task && config.Training && !config.Fine_Tune && config.output_activation() == Sigmoid {
+			return fmt.Errorf("Sigmoid outputs are between 0 and 1, so %s needs a target minimum and maximum to scale the targets into that range, or a linear output activation.", Regression_Task)
+		}
+		return nil
+	}
+	if config.Task != Regression_Task {
+		return fmt.Errorf("The target minimum and maximum are only used by the %s task.", Regression_Task)
+	}
+	if len(config.Target_Min) != config.Output_Count || len(config.Target_Max) != config.Output_Count {
+		return fmt.Errorf("There must be one target minimum and maximum for each output node.")
+	}
+	for i := 0; i < config.Output_Count; i++ {
+		if config.Target_Min[i] >= config.Target_Max[i] {
+			return fmt.Errorf("Each target maximum must be greater than its target minimum.")
+		}
+	}
+	return nil
+}
+
+//********************************************************************
+// Name:	scale_target
+// Description: This function scales a regression target into the 0 to
+//		1 range the config gives for its output, or leaves it
+//		alone when the config does not scale targets.
+// Return:	returns the scaled target.
+//********************************************************************
+
+func (config *Config) scale_target(output int, target float64) float64 {
+	if config.Target_Min == nil {
+		return target
+	}
+	return (target - config.Target_Min[output]) / (config.Target_Max[output] - config.Target_Min[output])
+}
+
+//********************************************************************
+// Name:	unscale_target
+// Description: This function undoes scale_target, turning an output
+//		of the network back into the units of the data set.
+// Return:	returns the unscaled value.
+//********************************************************************
+
+func (config *Config) unscale_target(output int, value float64) float64 {
+	if config.Target_Min == nil {
+		return value
+	}
+	return value * (config.Target_Max[output] - config.Target_Min[output]) + config.Target_Min[output]
+}
+
+//********************************************************************
+// Name:	metric_names
+// Description: This function names the results an evaluation reports
+//		for the config's task, in the order String gives them.
+// Return:	returns an array of the metric names.
+//********************************************************************
+
+func (config *Config) metric_names() []string {
+	if config.Task == Regression_Task {
+		return []string{"mse", "rmse", "mae", "r squared"}
+	}
+	if config.Task == Multi_Label_Task {
+		return []string{"subset accuracy", "hamming loss"}
+	}
+	return []string{"accuracy"}
+}
+
+//********************************************************************
+// Name:	evaluate_regression
+// Description: This function runs a regression test on the given data
+//		set. The loss is measured on the targets the network
+//		is trained towards, and the other results on the
+//		targets unscaled back into the units of the data set.
+//		R squared is worked out for each output, comparing its
+//		squared error to the variance of its target around the
+//		mean, and then averaged over the outputs. An output
+//		whose target never changes has an R squared of 0.
+// Return:	An Evaluation holding the loss, mean squared error,
+//		root mean squared error, mean absolute error and R
+//		squared.
+//********************************************************************
+
+func (network *Network) evaluate_regression(config *Config, data []Input) Evaluation {
+	loss_function := config.new_loss()
+	loss := 0.0
+	squared_error := 0.0
+	absolute_error := 0.0
+	means := make([]float64, network.Output_Count)
+	for data_index := 0; data_index < len(data); data_index++ {
+		for k := 0; k < network.Output_Count; k++ {
+			means[k] += config.unscale_target(k, data[data_index].Target[k]) / float64(len(data))
+		}
+	}
+
+	squared_errors := make([]float64, network.Output_Count)
+	variances := make([]float64, network.Output_Count)
+	predictions := network.predict(config, data)
+	for data_index := 0; data_index < len(data); data_index++ {
+		outputs := predictions[data_index]
+		loss += loss_function.loss(outputs, data[data_index].Target)
+		for k := 0; k < network.Output_Count; k++ {
+			target := config.unscale_target(k, data[data_index].Target[k])
+			difference := config.unscale_target(k, outputs[k]) - target
+			squared_errors[k] += difference * difference
+			absolute_error += math.Abs(difference)
+			variances[k] += (target - means[k]) * (target - means[k])
+		}
+	}
+
+	count := float64(len(data) * network.Output_Count)
+	r_squared := 0.0
+	for k := 0; k < network.Output_Count; k++ {
+		squared_error += squared_errors[k]
+		if variances[k] > 0 {
+			r_squared += (1 - squared_errors[k] / variances[k]) / float64(network.Output_Count)
+		}
+	}
+	return Evaluation{
+		Task                    : Regression_Task,
+		Loss                    : loss / float64(len(data)),
+		Mean_Squared_Error      : squared_error / count,
+		Root_Mean_Squared_Error : math.Sqrt(squared_error / count),
+		Mean_Absolute_Error     : absolute_error / count,
+		R_Squared               : r_squared,
+	}
+}
+
+//********************************************************************
+// Name:	Label_Score
+// Description: How well a multi label network finds one label.
+//		Precision is the fraction of the inputs it gave the
+//		label that really have it, Recall is the fraction of
+//		the inputs with the label that it found, and F1 is
+//		their harmonic mean.
+//********************************************************************
+
+type Label_Score struct {
+	Label                   string        `json:"label"`
+	Precision               float64       `json:"precision"`
+	Recall                  float64       `json:"recall"`
+	F1                      float64       `json:"f1"`
+}
+
+//********************************************************************
+// Name:	evaluate_multi_label
+// Description: This function runs a multi label test on the given
+//		data set. Each output is given its label when it is at
+//		least the config's label threshold. Accuracy is the
+//		percentage of inputs whose labels were all right, and
+//		the hamming loss is the fraction of labels that were
+//		wrong.
+// Return:	An Evaluation holding the loss, subset accuracy,
+//		hamming loss and the score of every label.
+//********************************************************************
+
+func (network *Network) evaluate_multi_label(config *Config, data []Input) Evaluation {
+	loss_function := config.new_loss()
+	loss := 0.0
+	hits := 0
+	wrong_labels := 0
+	true_positives := make([]int, network.Output_Count)
+	false_positives := make([]int, network.Output_Count)
+	false_negatives := make([]int, network.Output_Count)
+	predictions := network.predict(config, data)
+	for data_index := 0; data_index < len(data); data_index++ {
+		outputs := predictions[data_index]
+		loss += loss_function.loss(outputs, data[data_index].Target)
+		all_right := true
+		for k := 0; k < network.Output_Count; k++ {
+			predicted := outputs[k] >= config.Label_Threshold
+			actual := data[data_index].Labels[k]
+			if predicted && actual {
+				true_positives[k]++
+			} else if predicted {
+				false_positives[k]++
+			} else if actual {
+				false_negatives[k]++
+			}
+			if predicted != actual {
+				wrong_labels++
+				all_right = false
+			}
+		}
+		if all_right {
+			hits++
+		}
+	}
+
+	labels := config.class_labels()
+	var label_scores []Label_Score
+	for k := 0; k < network.Output_Count; k++ {
+		score := Label_Score{Label: labels[k]}
+		if true_positives[k] + false_positives[k] > 0 {
+			score.Precision = float64(true_positives[k]) / float64(true_positives[k] + false_positives[k])
+		}
+		if true_positives[k] + false_negatives[k] > 0 {
+			score.Recall = float64(true_positives[k]) / float64(true_positives[k] + false_negatives[k])
+		}
+		if score.Precision + score.Recall > 0 {
+			score.F1 = 2 * score.Precision * score.Recall / (score.Precision + score.Recall)
+		}
+		label_scores = append(label_scores, score)
+	}
+	return Evaluation{
+		Task             : Multi_Label_Task,
+		Accuracy         : float64(hits) / float64(len(data)) * 100,
+		Loss             : loss / float64(len(data)),
+		Hamming_Loss     : float64(wrong_labels) / float64(len(data) * network.Output_Count),
+		Label_Scores     : label_scores,
+	}
+}
@@ -0,0 +1,124 @@
+package dnn
+
+import (
+	"math"
+	"testing"
+)
+
+//********************************************************************
+// Name:	identity_network
+// Description: This function builds a network for the given task
+//		whose 2 linear outputs are its 2 inputs, passed through
+//		a linear hidden layer, so a test can choose the outputs
+//		it evaluates.
+// Return:	returns the config and the network.
+//********************************************************************
+
+func identity_network(task string) (*Config, *Network) {
+	config := New_Config()
+	config.Task = task
+	config.Input_Count = 3
+	config.Hidden_Count = []int{2}
+	config.Hidden_Layers = 1
+	config.Hidden_Activations = []string{Linear}
+	config.Output_Count = 2
+	config.Output_Activation = Linear
+	config.Loss = Squared_Error_Loss
+	network := New_Network(config, false)
+	network.Weights[0] = [][]float64{{0, 1, 0}, {0, 0, 1}}
+	network.Weights[1] = [][]float64{{0, 1, 0}, {0, 0, 1}}
+	return config, network
+}
+
+func TestMultiLabelMetrics(t *testing.T) {
+	config, network := identity_network(Multi_Label_Task)
+	data := []Input{
+		{Values: []float64{1, .9, .2}, Target: []float64{1, 0}, Labels: []bool{true, false}},
+		{Values: []float64{1, .8, .7}, Target: []float64{1, 0}, Labels: []bool{true, false}},
+		{Values: []float64{1, .1, .6}, Target: []float64{1, 1}, Labels: []bool{true, true}},
+		{Values: []float64{1, .3, .4}, Target: []float64{0, 0}, Labels: []bool{false, false}},
+	}
+	tests := []struct {
+		threshold               float64
+		accuracy                float64
+		hamming_loss            float64
+		scores                  []Label_Score
+	}{
+		{.5, 50, .25, []Label_Score{{"0", 1, 2.0 / 3, .8}, {"1", .5, 1, 2.0 / 3}}},
+		{.65, 50, .375, []Label_Score{{"0", 1, 2.0 / 3, .8}, {"1", 0, 0, 0}}},
+	}
+	for _, test := range tests {
+		config.Label_Threshold = test.threshold
+		evaluation := network.Evaluate(config, data)
+		if math.Abs(evaluation.Accuracy - test.accuracy) > 1e-9 {
+			t.Errorf("At a threshold of %g the subset accuracy was %g, but it should be %g.",
+				test.threshold, evaluation.Accuracy, test.accuracy)
+		}
+		if math.Abs(evaluation.Hamming_Loss - test.hamming_loss) > 1e-9 {
+			t.Errorf("At a threshold of %g the hamming loss was %g, but it should be %g.",
+				test.threshold, evaluation.Hamming_Loss, test.hamming_loss)
+		}
+		if len(evaluation.Label_Scores) != len(test.scores) {
+			t.Fatalf("There were %d label scores, but there should be %d.", len(evaluation.Label_Scores), len(test.scores))
+		}
+		for k, expected := range test.scores {
+			score := evaluation.Label_Scores[k]
+			if score.Label != expected.Label || math.Abs(score.Precision - expected.Precision) > 1e-9 ||
+				math.Abs(score.Recall - expected.Recall) > 1e-9 || math.Abs(score.F1 - expected.F1) > 1e-9 {
+				t.Errorf("At a threshold of %g label %d scored %+v, but it should score %+v.",
+					test.threshold, k, score, expected)
+			}
+		}
+	}
+}
+
+func TestRegressionRSquared(t *testing.T) {
+	config, network := identity_network(Regression_Task)
+	data := []Input{
+		{Values: []float64{1, 1, .5}, Target: []float64{0, 0}},
+		{Values: []float64{1, 9, .5}, Target: []float64{10, 1}},
+		{Values: []float64{1, 21, .5}, Target: []float64{20, 0}},
+		{Values: []float64{1, 29, .5}, Target: []float64{30, 1}},
+	}
+	evaluation := network.Evaluate(config, data)
+	// The first output explains all but 4 of its variance of 500, and the
+	// second output none of its variance of 1.
+	expected := (1 - 4.0 / 500) / 2
+	if math.Abs(evaluation.R_Squared - expected) > 1e-9 {
+		t.Errorf("R squared was %g, but the average of each output's should be %g.", evaluation.R_Squared, expected)
+	}
+	if math.Abs(evaluation.Mean_Squared_Error - 5.0 / 8) > 1e-9 {
+		t.Errorf("The mean squared error was %g, but it should be %g.", evaluation.Mean_Squared_Error, 5.0 / 8)
+	}
+}
+
+func TestRegressionOutputActivation(t *testing.T) {
+	tests := []struct {
+		name                    string
+		output_activation       string
+		scaled                  bool
+		valid                   bool
+	}{
+		{"linear", Linear, false, true},
+		{"sigmoid", Sigmoid, false, false},
+		{"sigmoid with a target range", Sigmoid, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := gradient_check_config([]int{2}, Tanh, test.output_activation, Squared_Error_Loss)
+			config.Task = Regression_Task
+			config.Training = true
+			if test.scaled {
+				config.Target_Min = []float64{0, 0, 0}
+				config.Target_Max = []float64{1, 1, 1}
+			}
+			err := config.task_error_check()
+			if test.valid && err != nil {
+				t.Error(err)
+			}
+			if !test.valid && err == nil {
+				t.Error("A regression network with sigmoid outputs and unscaled targets was allowed.")
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package dnn
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+)
+
+//********************************************************************
+// Name:	Train
+// Description: This function trains the network for however many
+//		epochs are specified in the config, and also runs a
+//		test in between every epoch for accuracy data. The
+//		validation data is never trained on, it is only tested
+//		each epoch, and can be left nil.
+// Return:	returns a string holding the accuracies.
+//********************************************************************
+
+func (network *Network) Train(config *Config, training_data []Input, validation_data []Input) string {
+	return network.Resume(config, training_data, validation_data, network.New_Training_State(config))
+}
+
+//********************************************************************
+// Name:	Resume
+// Description: This function carries on training the network from
+//		the training state, which is either new or comes from
+//		a checkpoint. A checkpoint is saved every time the
+//		config's checkpoint epoch count finishes.
+// Return:	returns a string holding the accuracies.
+//********************************************************************
+
+func (network *Network) Resume(config *Config, training_data []Input, validation_data []Input, state *Training_State) string {
+	generator := state.Random.generator()
+	frozen := config.frozen_layers(len(network.Weights))
+	batch := network.new_gradient()
+	layers := network.layers()
+	network.bind(layers, network.Weights, batch.Weights)
+	loss := config.new_loss()
+	batch_size := config.Batch_Size
+	if batch_size < 1 {
+		batch_size = 1
+	}
+	early_stopping := config.Patience > 0 && validation_data != nil
+	schedule := config.Learning_Rate_Schedule
+	batch_count := (len(training_data) + batch_size - 1) / batch_size
+	total_steps := config.Epoch_Count
+	if schedule.Per_Batch {
+		total_steps *= batch_count
+	}
+	stopped := false
+
+	for epoch_index := state.Epoch; epoch_index < config.Epoch_Count; epoch_index++ {
+		progress := ""
+		if config.Test_While_Training {
+			training_results := network.Evaluate(config, training_data)
+			state.History = append(state.History, training_results)
+			progress += training_results.progress("current")
+		}
+		stop := false
+		if validation_data != nil {
+			validation_results := network.Evaluate(config, validation_data)
+			state.Validation_History = append(state.Validation_History, validation_results)
+			progress += validation_results.progress("validation")
+			if early_stopping {
+				stop = state.Early_Stopping.track(config, network, epoch_index, validation_results)
+			}
+			config.plateau(&state.Schedule, validation_results.Loss)
+		}
+		learning_rate := config.learning_rate(&state.Schedule, epoch_index, total_steps)
+		if schedule.Per_Batch {
+			learning_rate = config.learning_rate(&state.Schedule, epoch_index * batch_count, total_steps)
+		}
+		progress += fmt.Sprintf(", learning rate is %g", learning_rate)
+		if config.Progress_Tracker && epoch_index % config.Epoch_Update == 0 {
+			log.Print("Beggining Epoch #", epoch_index, progress)
+		}
+		if stop {
+			log.Print("Stopping early, the ", config.Early_Stopping_Metric, " has not improved for ",
+				config.Patience, " epochs")
+			stopped = true
+			break
+		}
+		// A new order is drawn every epoch rather than shuffling the last one, so
+		// the order only depends on the random state saved in a checkpoint.
+		order := make([]int, len(training_data))
+		for i := range order {
+			order[i] = i
+		}
+		if config.Shuffle_Data {
+			order = generator.Perm(len(training_data))
+		}
+		for batch_start := 0; batch_start < len(training_data); batch_start += batch_size {
+			batch_end := batch_start + batch_size
+			if batch_end > len(training_data) {
+				batch_end = len(training_data)
+			}
+			batch.reset()
+			var batch_data []Input
+			for _, data_index := range order[batch_start:batch_end] {
+				batch_data = append(batch_data, training_data[data_index])
+			}
+			backpropagate(layers, batch_data, generator, batch, loss)
+			if schedule.Per_Batch {
+				learning_rate = config.learning_rate(&state.Schedule, epoch_index * batch_count + batch_start / batch_size, total_steps)
+			}
+			network.apply_gradient(config, layers, batch, learning_rate, &state.Optimizer, frozen)
+		}
+
+		state.Epoch = epoch_index + 1
+		if config.Checkpoint_Epochs > 0 && state.Epoch % config.Checkpoint_Epochs == 0 {
+			// A failed checkpoint is only logged, it should not end a long run early.
+			err := New_Checkpoint(network, config, state).Save(config.Checkpoint_File)
+			if err != nil {
+				log.Print("Error while saving the checkpoint for epoch #", state.Epoch, "\n", err)
+			} else if config.Progress_Tracker {
+				log.Print("Saved a checkpoint after epoch #", state.Epoch, " to ", config.Checkpoint_File)
+			}
+		}
+	}
+	if config.Progress_Tracker && !stopped {
+		log.Print("The final Epoch has completed")
+	}
+	if early_stopping {
+		// the weights left by the last epoch still need to be compared to the best
+		if !stopped {
+			state.Early_Stopping.track(config, network, state.Epoch, network.Evaluate(config, validation_data))
+		}
+		state.Early_Stopping.restore(config, network)
+	}
+
+	// every epoch's accuracy is followed by its average loss
+	training_str := "training data accuracy\n"
+	for _, training_results := range state.History {
+		training_str += fmt.Sprintf("%v, %f\n", training_results, training_results.Loss)
+		if config.CM_Enabled {
+			training_str += Csv_Styled_Confusion_Matrix(training_results.Confusion_Matrix)
+		}
+	}
+	training_str += ", \n"
+	training_results := network.Evaluate(config, training_data)
+	training_str += fmt.Sprintf("%v, %f\n", training_results, training_results.Loss)
+	if config.CM_Enabled {
+		training_str += Csv_Styled_Confusion_Matrix(training_results.Confusion_Matrix)
+	}
+	training_str += Csv_Styled_Label_Scores(training_results.Label_Scores)
+
+	if validation_data != nil {
+		// Each epoch's validation results sit next to its training results, the
+		// training cells are left empty if they were not collected.
+		training_str += "\nvalidation data accuracy\n"
+		header := "epoch"
+		for _, data_set := range []string{"training", "validation"} {
+			for _, metric := range append(config.metric_names(), "loss") {
+				header += ", " + data_set + " " + metric
+			}
+		}
+		training_str += header + "\n"
+		for epoch_index, validation_results := range state.Validation_History {
+			training_cells := " " + strings.Repeat(", ", len(config.metric_names()))
+			if epoch_index < len(state.History) {
+				training_cells = fmt.Sprintf("%v, %f", state.History[epoch_index], state.History[epoch_index].Loss)
+			}
+			training_str += fmt.Sprintf("%d, %s, %v, %f\n", epoch_index, training_cells,
+				validation_results, validation_results.Loss)
+		}
+		validation_results := network.Evaluate(config, validation_data)
+		training_str += fmt.Sprintf("final, %v, %f, %v, %f\n", training_results, training_results.Loss,
+			validation_results, validation_results.Loss)
+		if config.CM_Enabled {
+			training_str += Csv_Styled_Confusion_Matrix(validation_results.Confusion_Matrix)
+		}
+		training_str += Csv_Styled_Label_Scores(validation_results.Label_Scores)
+	}
+	return training_str
+}
+
+//********************************************************************
+// Name:	gradient
+// Description: The gradient of the error summed over a batch of
+//		inputs. Weights is shaped like the network's weights,
+//		and count is the number of inputs.
+//********************************************************************
+
+type gradient struct {
+	Weights                 [][][]float64
+	count                   int
+}
+
+//********************************************************************
+// Name:	new_gradient
+// Description: This function creates an empty gradient shaped like
+//		the network.
+// Return:	returns a pointer to the new gradient.
+//********************************************************************
+
+func (network *Network) new_gradient() *gradient {
+	return &gradient{Weights: network.create_weights()}
+}
+
+//********************************************************************
+// Name:	reset
+// Description: This function empties the gradient for the next batch.
+//********************************************************************
+
+func (batch *gradient) reset() {
+	for layer_index := 0; layer_index < len(batch.Weights); layer_index++ {
+		for node_index := 0; node_index < len(batch.Weights[layer_index]); node_index++ {
+			for weight_index := 0; weight_index < len(batch.Weights[layer_index][node_index]); weight_index++ {
+				batch.Weights[layer_index][node_index][weight_index] = 0
+			}
+		}
+	}
+	batch.count = 0
+}
+
+//********************************************************************
+// Name:	backpropagate
+// Description: This function runs a batch of inputs through the
+//		layers while training and adds the gradient of their
+//		loss onto the gradients bound to the layers. The
+//		gradient is passed back from the last layer down to
+//		the first layer with weights, since nothing before it
+//		has anything to train. The weights themselves are not
+//		changed.
+//********************************************************************
+
+func backpropagate(layers []Layer, data []Input, generator *rand.Rand, batch *gradient, loss loss_function) {
+	values := make([][]float64, len(data))
+	for s, data_point := range data {
+		values[s] = data_point.Values[1:]
+	}
+	outputs := forward(layers, values, true, generator)
+	batch.count += len(data)
+
+	// The output activation is folded into the loss's error terms, which
+	// point down hill, so the gradient is their negative.
+	last := len(layers)
+	output_activation := Linear
+	dot_products := outputs
+	if activation, ok := layers[last - 1].(*activation_layer); ok {
+		last--
+		output_activation = activation.name
+		dot_products = activation.inputs
+	}
+	gradients := make([][]float64, len(data))
+	for s, data_point := range data {
+		gradients[s] = output_error_terms(loss, output_activation, dot_products[s], outputs[s], data_point.Target)
+		for k := range gradients[s] {
+			gradients[s][k] = -gradients[s][k]
+		}
+	}
+
+	first := 0
+	for first < last && len(layers[first].shapes()) == 0 {
+		first++
+	}
+	for layer_index := last - 1; layer_index >= first; layer_index-- {
+		gradients = layers[layer_index].backward(gradients, layer_index > first)
+	}
+}
+
+//********************************************************************
+// Name:	apply_gradient
+// Description: This function steps every weight using the batch's
+//		average gradient plus the gradient of the config's
+//		penalties, the learning rate and the config's
+//		optimizer, then holds each layer of weights to its max
+//		norm. The penalties, max norms and AdamW's weight
+//		decay only apply to the layers that are regularized,
+//		and skip their biases unless the config includes them.
+//		Frozen layers of weights are left alone, and the
+//		layers that keep statistics while training update
+//		them.
+//********************************************************************
+
+func (network *Network) apply_gradient(config *Config, layers []Layer, batch *gradient, learning_rate float64,
+	state *Optimizer_State, frozen []bool) {
+	if batch.count == 0 {
+		return
+	}
+	state.Step++
+	rule := config.new_optimizer(state.Step)
+	decay := config.Optimizer.decay()
+	layer_index := 0
+	for _, layer := range layers {
+		trained := false
+		for _, weights := range layer.parameters() {
+			if frozen[layer_index] {
+				layer_index++
+				continue
+			}
+			trained = true
+			for k := 0; k < len(weights); k++ {
+				for j := 0; j < len(weights[k]); j++ {
+					weight := weights[k][j]
+					gradient := batch.Weights[layer_index][k][j] / float64(batch.count)
+					regularized := layer.regularized() && config.Regularization.regularized(j)
+					if regularized {
+						gradient += config.Regularization.gradient(j, weight)
+					}
+					weights[k][j] = rule.update(weight, gradient, learning_rate,
+						&state.First_Moment[layer_index][k][j], &state.Second_Moment[layer_index][k][j])
+					if regularized {
+						weights[k][j] -= learning_rate * decay * weight
+					}
+				}
+			}
+			if layer.regularized() {
+				config.Regularization.constrain(network.Weights, layer_index)
+			}
+			layer_index++
+		}
+		if updater, ok := layer.(stateful); ok && trained {
+			updater.update()
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/JosephJindrich/Deep-Neural-Network-Trainer
+
+go 1.13